@@ -0,0 +1,135 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+)
+
+// AppInfoExtension interprets the appinfo dialect of a schema: the calls it
+// describes and how each call uses a field. Every method accepts a nil
+// annotation, which fields without xs:annotation have.
+type AppInfoExtension interface {
+	// Calls returns the calls of the schema, generated as request and
+	// response types. Schemas without calls return nil and have a type
+	// generated for each top-level element instead.
+	Calls(s *schema) []string
+
+	// Skip reports whether the field is left out of every exported call.
+	Skip(a *annotation) bool
+	// IncludedIn reports whether the request (or response) of callName
+	// has the field.
+	IncludedIn(a *annotation, callName string, request bool) bool
+	// RequiredFor reports whether the request of callName requires the field.
+	RequiredFor(a *annotation, callName string) bool
+	// RequiredInputFor returns how the request of callName takes the field:
+	// "Yes", "No", "Conditionally" or "" if it does not.
+	RequiredInputFor(a *annotation, callName string) string
+	// ReturnedFor returns how the response of callName returns the field:
+	// "Always", "Conditionally" or "" if it does not.
+	ReturnedFor(a *annotation, callName string) string
+	// RequiredIf returns the conditions making the field required in the
+	// request of callName. parent is the normalised path of its parent.
+	RequiredIf(a *annotation, callName, parent string) []Condition
+	// DefaultFor returns the default of the field in the request of callName.
+	DefaultFor(a *annotation, callName string) (string, bool)
+	// MinOccurs returns the minimum number of values of the field for
+	// callName, on top of the XSD minOccurs.
+	MinOccurs(a *annotation, callName string) (int, bool)
+	// ValidationRules returns the limits of the field for callName.
+	ValidationRules(a *annotation, callName string) (ValidationContainer, bool)
+	// ListBasedOn returns the simple type holding the values of a field
+	// typed as a plain string.
+	ListBasedOn(a *annotation) (string, bool)
+}
+
+// AppInfoExtensions holds the dialects selectable with -appinfo.
+var AppInfoExtensions = map[string]AppInfoExtension{
+	"ebay":    ebayAppInfo{},
+	"generic": genericAppInfo{},
+	"soa":     soaAppInfo{},
+}
+
+var appInfoExt AppInfoExtension = ebayAppInfo{}
+
+// loadAppInfo selects the -appinfo dialect.
+func loadAppInfo() {
+	ext, ok := AppInfoExtensions[*appInfoName]
+	if !ok {
+		var names []string
+		for name := range AppInfoExtensions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		log.Fatalf("unknown -appinfo %q, use one of: %s", *appInfoName, strings.Join(names, ", "))
+	}
+	appInfoExt = ext
+}
+
+// genericAppInfo ignores appinfo: the schema has no calls and every field of
+// a type is generated.
+type genericAppInfo struct{}
+
+func (genericAppInfo) Calls(s *schema) []string { return nil }
+
+func (genericAppInfo) Skip(a *annotation) bool { return false }
+
+func (genericAppInfo) IncludedIn(a *annotation, callName string, request bool) bool { return true }
+
+func (genericAppInfo) RequiredFor(a *annotation, callName string) bool { return false }
+
+func (genericAppInfo) RequiredInputFor(a *annotation, callName string) string { return "" }
+
+func (genericAppInfo) ReturnedFor(a *annotation, callName string) string { return "" }
+
+func (genericAppInfo) RequiredIf(a *annotation, callName, parent string) []Condition { return nil }
+
+func (genericAppInfo) DefaultFor(a *annotation, callName string) (string, bool) { return "", false }
+
+func (genericAppInfo) MinOccurs(a *annotation, callName string) (int, bool) { return 0, false }
+
+func (genericAppInfo) ValidationRules(a *annotation, callName string) (ValidationContainer, bool) {
+	return nil, false
+}
+
+func (genericAppInfo) ListBasedOn(a *annotation) (string, bool) { return "", false }
+
+// soaAppInfo reads the schemas of eBay's SOA APIs (Finding, Merchandising),
+// whose appinfo is not eBay's: the calls are found like in eBay's schemas and
+// every field of their types is generated. Only the required inputs are read
+// from their lower case callInfo.
+type soaAppInfo struct {
+	genericAppInfo
+}
+
+// soaCallInfo is the callInfo of the SOA APIs' appinfo.
+type soaCallInfo struct {
+	AllCalls      *string  `xml:"allCalls"`
+	CallName      []string `xml:"callName"`
+	RequiredInput string   `xml:"requiredInput"`
+}
+
+func (soaAppInfo) Calls(s *schema) []string { return ebayAppInfo{}.Calls(s) }
+
+func (i soaAppInfo) RequiredFor(a *annotation, callName string) bool {
+	return i.RequiredInputFor(a, callName) == "Yes"
+}
+
+// RequiredInputFor compares the operation names of callName case
+// insensitively, findItemsByKeywords is the call FindItemsByKeywords.
+func (soaAppInfo) RequiredInputFor(a *annotation, callName string) string {
+	if a == nil {
+		return ""
+	}
+	for _, ci := range a.AppInfo.SOACallInfo {
+		if ci.AllCalls != nil {
+			return ci.RequiredInput
+		}
+		for _, name := range ci.CallName {
+			if strings.EqualFold(name, callName) {
+				return ci.RequiredInput
+			}
+		}
+	}
+	return ""
+}
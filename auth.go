@@ -0,0 +1,231 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+)
+
+// authCalls are the calls of the Auth'n'Auth consent flow.
+var authCalls = []string{"GetSessionID", "FetchToken", "GetTokenStatus"}
+
+// writeAuth writes the helpers running the Auth'n'Auth consent flow to
+// -auth-go.
+func writeAuth() {
+	if *authGoFile == "" {
+		return
+	}
+	if *apiName != "trading" {
+		log.Fatal("-auth-go needs -api trading")
+	}
+	for _, call := range authCalls {
+		if !contains(exportedElements, call) {
+			log.Fatalf("-auth-go needs the %s call, export it with -e", call)
+		}
+		if Validator[call].Len() == 0 {
+			log.Fatalf("-auth-go needs the %s requester, which is only generated for calls with validation rules", call)
+		}
+	}
+
+	b := NewBuffer()
+	b.Sprintf("// Code generated by xsdbay. DO NOT EDIT.\r\n\r\npackage ebaysvc\r\n\r\nimport (\r\n\"context\"\r\n\"errors\"\r\n\"fmt\"\r\n\"net/url\"\r\n\"sync\"\r\n\"time\"\r\n)\r\n")
+	b.Sprintf("%s", templateAuth)
+	if err := ioutil.WriteFile(*authGoFile, formatCode(b.Bytes()), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var templateAuth = `
+// AuthToken is the Auth'n'Auth token of a user.
+type AuthToken struct {
+	Token string
+	// Expiry is the hard expiration time of the token.
+	Expiry time.Time
+}
+
+// Credentials returns the credentials sending the token.
+func (t *AuthToken) Credentials() Credentials {
+	return AuthNAuth(t.Token)
+}
+
+// TokenStore keeps the tokens of the users of an application, e.g. in a
+// database. Load returns nil, nil for users without a token.
+type TokenStore interface {
+	Load(ctx context.Context, user string) (*AuthToken, error)
+	Save(ctx context.Context, user string, token *AuthToken) error
+}
+
+// MemoryTokenStore is a TokenStore keeping the tokens in memory.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*AuthToken
+}
+
+func (s *MemoryTokenStore) Load(ctx context.Context, user string) (*AuthToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[user], nil
+}
+
+func (s *MemoryTokenStore) Save(ctx context.Context, user string, token *AuthToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = make(map[string]*AuthToken)
+	}
+	s.tokens[user] = token
+	return nil
+}
+
+// SignInURL is eBay's production sign-in page. The sandbox's is
+// https://signin.sandbox.ebay.com/ws/eBayISAPI.dll.
+const SignInURL = "https://signin.ebay.com/ws/eBayISAPI.dll"
+
+var (
+	ErrTokenNotFound = errors.New("no token stored for the user")
+	ErrTokenInactive = errors.New("token is not active")
+)
+
+// AuthNAuthFlow runs the Auth'n'Auth consent flow of an application. The
+// calls it makes need APIDevName, APIAppName and APICertName.
+//
+//	flow := &AuthNAuthFlow{RuName: ruName, SiteID: "0", Store: store}
+//	sessionID, signIn, err := flow.Start(ctx)
+//	// Send the user to signIn and, once back on the accept URL of the RuName:
+//	token, err := flow.Finish(ctx, user, sessionID)
+//	// Later:
+//	credentials, err := flow.Credentials(ctx, user)
+type AuthNAuthFlow struct {
+	// RuName is the eBay Redirect URL name of the application.
+	RuName string
+	SiteID string
+	Store  TokenStore
+
+	// SignInURL is the sign-in page. Default: SignInURL
+	SignInURL string
+
+	// Warn, when set, is called by Check for tokens expiring within
+	// WarnBefore (default: 30 days), so the user can be asked to sign in
+	// again in time.
+	Warn       func(user string, token *AuthToken)
+	WarnBefore time.Duration
+}
+
+// Start creates a session with GetSessionID and returns its ID and the URL
+// the user signs in at to grant the application access.
+func (f *AuthNAuthFlow) Start(ctx context.Context) (sessionID, signIn string, err error) {
+	request := &GetSessionIDRequestType{}
+	request.RuName.Set(f.RuName)
+	response, err := request.RequestContext(ctx, AuthNAuth(""), f.SiteID)
+	if err != nil {
+		return "", "", err
+	}
+	if err := authFailure("GetSessionID", string(response.Ack), response.Errors); err != nil {
+		return "", "", err
+	}
+
+	sessionID = response.SessionID.Value()
+	signIn = f.SignInURL
+	if signIn == "" {
+		signIn = SignInURL
+	}
+	signIn += "?SignIn&" + url.Values{"runame": {f.RuName}, "SessID": {sessionID}}.Encode()
+	return sessionID, signIn, nil
+}
+
+// Finish fetches the token of a session the user granted access in with
+// FetchToken and saves it as the token of user.
+func (f *AuthNAuthFlow) Finish(ctx context.Context, user, sessionID string) (*AuthToken, error) {
+	request := &FetchTokenRequestType{}
+	request.SessionID.Set(sessionID)
+	response, err := request.RequestContext(ctx, AuthNAuth(""), f.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authFailure("FetchToken", string(response.Ack), response.Errors); err != nil {
+		return nil, err
+	}
+
+	token := &AuthToken{Token: response.EBayAuthToken.Value()}
+	if token.Expiry, err = parseAuthTime(response.HardExpirationTime.Value()); err != nil {
+		return nil, err
+	}
+	if err := f.Store.Save(ctx, user, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Credentials returns the credentials of the stored token of user.
+func (f *AuthNAuthFlow) Credentials(ctx context.Context, user string) (Credentials, error) {
+	token, err := f.Store.Load(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrTokenNotFound
+	}
+	return token.Credentials(), nil
+}
+
+// Check asks eBay for the status of the stored token of user with
+// GetTokenStatus, saves its current expiration time and calls Warn if it
+// expires soon. Tokens expired or revoked return ErrTokenInactive.
+func (f *AuthNAuthFlow) Check(ctx context.Context, user string) (*AuthToken, error) {
+	token, err := f.Store.Load(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	response, err := (&GetTokenStatusRequestType{}).RequestContext(ctx, token.Credentials(), f.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authFailure("GetTokenStatus", string(response.Ack), response.Errors); err != nil {
+		return nil, err
+	}
+	status := response.TokenStatus
+	if status == nil {
+		return nil, fmt.Errorf("GetTokenStatus: no token status returned")
+	}
+	if string(status.Status) != "Active" {
+		return nil, fmt.Errorf("%w: %s", ErrTokenInactive, status.Status)
+	}
+
+	if expiry, err := parseAuthTime(status.ExpirationTime.Value()); err == nil && !expiry.IsZero() && !expiry.Equal(token.Expiry) {
+		token = &AuthToken{Token: token.Token, Expiry: expiry}
+		if err := f.Store.Save(ctx, user, token); err != nil {
+			return nil, err
+		}
+	}
+
+	warnBefore := f.WarnBefore
+	if warnBefore == 0 {
+		warnBefore = 30 * 24 * time.Hour
+	}
+	if f.Warn != nil && !token.Expiry.IsZero() && time.Until(token.Expiry) < warnBefore {
+		f.Warn(user, token)
+	}
+	return token, nil
+}
+
+// authFailure returns the error of a failed call of the flow.
+func authFailure(call, ack string, errs []ErrorType) error {
+	if ack != "Failure" && ack != "PartialFailure" {
+		return nil
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("%s failed", call)
+	}
+	return fmt.Errorf("%s failed: %s (%s)", call, errs[0].ShortMessage.Value(), errs[0].ErrorCode.Value())
+}
+
+func parseAuthTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+`
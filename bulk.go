@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"sort"
+)
+
+// bulkCalls are the calls the bulk data exchange uploads.
+var bulkCalls = []string{
+	"AddFixedPriceItem",
+	"AddItem",
+	"EndFixedPriceItem",
+	"EndItem",
+	"OrderAck",
+	"RelistFixedPriceItem",
+	"RelistItem",
+	"ReviseFixedPriceItem",
+	"ReviseInventoryStatus",
+	"ReviseItem",
+	"SetShipmentTrackingInfo",
+	"UploadSiteHostedPictures",
+	"VerifyAddFixedPriceItem",
+	"VerifyAddItem",
+}
+
+// bulkExchange returns the BulkRequest methods of the exported bulk calls,
+// the response types BulkReader decodes and the writer and reader.
+func bulkExchange() string {
+	if *apiName != "trading" {
+		log.Fatal("-bulk needs -api trading")
+	}
+	var calls []string
+	for _, call := range bulkCalls {
+		if contains(exportedElements, call) {
+			calls = append(calls, call)
+		}
+	}
+	sort.Strings(calls)
+
+	b := NewBuffer()
+	for _, call := range calls {
+		b.Sprintf("func (x *%[1]sRequestType) bulkCall() string { return %[1]q }\r\n\r\n", call)
+	}
+	b.Sprintf("// bulkResponses creates the response types of the bulk calls, keyed by\r\n// element name.\r\n")
+	b.Sprintf("var bulkResponses = map[string]func() interface{}{\r\n")
+	for _, call := range calls {
+		b.Sprintf("%q: func() interface{} { return &%sResponseType{} },\r\n", call+"Response", call)
+	}
+	b.Sprintf("}\r\n")
+	return b.String() + templateBulk
+}
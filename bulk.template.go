@@ -0,0 +1,158 @@
+package main
+
+// Bulk data exchange files. Emitted with -bulk.
+var templateBulk = `
+// BulkRequest is a request of a call the bulk data exchange (Large Merchant
+// Services) uploads.
+type BulkRequest interface {
+	bulkCall() string
+}
+
+// BulkWriter streams requests into a BulkDataExchangeRequests file, gzip
+// compressed if created so:
+//
+//	w := NewBulkWriter(f, "0", true)
+//	for _, item := range items {
+//		if err := w.Write(item); err != nil { ... }
+//	}
+//	err := w.Close()
+type BulkWriter struct {
+	enc    *xml.Encoder
+	gz     *gzip.Writer
+	siteID  string
+	started bool
+	calls   int
+	err     error
+}
+
+func NewBulkWriter(w io.Writer, siteID string, compress bool) *BulkWriter {
+	b := &BulkWriter{siteID: siteID}
+	if compress {
+		b.gz = gzip.NewWriter(w)
+		w = b.gz
+	}
+	b.enc = xml.NewEncoder(w)
+	return b
+}
+
+var bulkRoot = xml.StartElement{
+	Name: xml.Name{Local: "BulkDataExchangeRequests"},
+	Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: xmlNamespace}},
+}
+
+// Write appends requests to the file. Requests are checked with Validate()
+// when RequestValidation is set; a request failing it is not written. The
+// file is unusable after any other error.
+func (b *BulkWriter) Write(requests ...BulkRequest) error {
+	if b.err != nil {
+		return b.err
+	}
+	if !b.started {
+		b.err = b.writeHeader()
+	}
+	for _, request := range requests {
+		if b.err != nil {
+			break
+		}
+		if v, ok := request.(interface{ Validate() error }); ok && RequestValidation {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("bulk request %d (%s): %w", b.calls+1, request.bulkCall(), err)
+			}
+		}
+		b.err = b.enc.Encode(request)
+		b.calls++
+	}
+	return b.err
+}
+
+func (b *BulkWriter) writeHeader() error {
+	b.started = true
+	declaration := xml.ProcInst{Target: "xml", Inst: []byte(` + "`version=\"1.0\" encoding=\"UTF-8\"`" + `)}
+	if err := b.enc.EncodeToken(declaration); err != nil {
+		return err
+	}
+	if err := b.enc.EncodeToken(bulkRoot); err != nil {
+		return err
+	}
+	header := struct {
+		SiteID  string
+		Version string
+	}{b.siteID, APICompatibilityLevel}
+	return b.enc.EncodeElement(header, xml.StartElement{Name: xml.Name{Local: "Header"}})
+}
+
+// Close ends the file and flushes it. It does not close the underlying
+// writer.
+func (b *BulkWriter) Close() error {
+	if b.err != nil {
+		return b.err
+	}
+	if !b.started {
+		if b.err = b.writeHeader(); b.err != nil {
+			return b.err
+		}
+	}
+	if err := b.enc.EncodeToken(bulkRoot.End()); err != nil {
+		return err
+	}
+	if err := b.enc.Flush(); err != nil {
+		return err
+	}
+	if b.gz != nil {
+		return b.gz.Close()
+	}
+	return nil
+}
+
+// BulkReader reads the responses of a BulkDataExchangeResponses file one at a
+// time, gzip compressed or not.
+type BulkReader struct {
+	dec  *xml.Decoder
+	root bool
+}
+
+func NewBulkReader(r io.Reader) (*BulkReader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return &BulkReader{dec: xml.NewDecoder(gz)}, nil
+	}
+	return &BulkReader{dec: xml.NewDecoder(br)}, nil
+}
+
+// Next returns the next response, e.g. *AddFixedPriceItemResponseType, and
+// io.EOF after the last one. Responses of calls not generated are skipped and
+// reported with an error; reading can go on after it.
+func (b *BulkReader) Next() (interface{}, error) {
+	for {
+		tok, err := b.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !b.root {
+				b.root = true
+				continue
+			}
+			newResponse, ok := bulkResponses[t.Name.Local]
+			if !ok {
+				if err := b.dec.Skip(); err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("bulk data exchange: unknown response %s", t.Name.Local)
+			}
+			response := newResponse()
+			if err := b.dec.DecodeElement(response, &t); err != nil {
+				return nil, err
+			}
+			return response, nil
+		case xml.EndElement:
+			return nil, io.EOF
+		}
+	}
+}
+`
@@ -0,0 +1,132 @@
+package main
+
+// Credentials of the requesters of APIs taking the user's token.
+var templateCredentials = `
+// Credentials authenticate the calls made for a user: AuthNAuth sends an
+// Auth'n'Auth token in RequesterCredentials, OAuth sends OAuth user access
+// tokens in the X-EBAY-API-IAF-TOKEN header.
+type Credentials interface {
+	// authorize adds the credentials to req and returns the token written to
+	// RequesterCredentials, empty for credentials sent in a header.
+	authorize(req *xbayRequester) (string, error)
+}
+
+var ErrCredentialsNotSet error = errors.New("credentials are not set")
+
+// ErrOAuthTokenNotSet is returned for a token source giving no access token.
+var ErrOAuthTokenNotSet error = errors.New("OAuth token source returned no access token")
+
+// AuthNAuth is an Auth'n'Auth token.
+type AuthNAuth string
+
+func (t AuthNAuth) authorize(req *xbayRequester) (string, error) {
+	return string(t), nil
+}
+
+// OAuthToken is an OAuth user access token.
+type OAuthToken struct {
+	AccessToken string
+	Expiry      time.Time
+}
+
+// Valid reports whether the token is set and not about to expire.
+func (t *OAuthToken) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Until(t.Expiry) > time.Minute)
+}
+
+// OAuthTokenSource returns the current OAuth user access token, e.g. a
+// RefreshingTokenSource.
+type OAuthTokenSource interface {
+	Token(ctx context.Context) (*OAuthToken, error)
+}
+
+// OAuth returns the credentials sending the tokens of source.
+func OAuth(source OAuthTokenSource) Credentials {
+	return oauthCredentials{source}
+}
+
+type oauthCredentials struct {
+	source OAuthTokenSource
+}
+
+func (c oauthCredentials) authorize(req *xbayRequester) (string, error) {
+	token, err := c.source.Token(req.ctx)
+	if err != nil {
+		return "", err
+	}
+	if token == nil || token.AccessToken == "" {
+		return "", ErrOAuthTokenNotSet
+	}
+	req.header.Set("X-EBAY-API-IAF-TOKEN", token.AccessToken)
+	return "", nil
+}
+
+// RefreshingTokenSource gets user access tokens from a refresh token and
+// keeps them until they are about to expire.
+type RefreshingTokenSource struct {
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	// Scopes of the access tokens. Default: the scopes of the refresh token
+	Scopes []string
+	// TokenURL is the token endpoint. Default: OAuthTokenURL
+	TokenURL string
+
+	mu    sync.Mutex
+	token *OAuthToken
+}
+
+// OAuthTokenURL is eBay's production token endpoint. The sandbox's is
+// https://api.sandbox.ebay.com/identity/v1/oauth2/token.
+const OAuthTokenURL = "https://api.ebay.com/identity/v1/oauth2/token"
+
+func (s *RefreshingTokenSource) Token(ctx context.Context) (*OAuthToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {s.RefreshToken}}
+	if len(s.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.Scopes, " "))
+	}
+	tokenURL := s.TokenURL
+	if tokenURL == "" {
+		tokenURL = OAuthTokenURL
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(s.ClientID, s.ClientSecret)
+
+	response, err := HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	var answer struct {
+		AccessToken      string ` + "`json:\"access_token\"`" + `
+		ExpiresIn        int64  ` + "`json:\"expires_in\"`" + `
+		Error            string ` + "`json:\"error\"`" + `
+		ErrorDescription string ` + "`json:\"error_description\"`" + `
+	}
+	if err := json.NewDecoder(response.Body).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("oauth: %s: %w", response.Status, err)
+	}
+	if answer.Error != "" {
+		return nil, fmt.Errorf("oauth: %s: %s", answer.Error, answer.ErrorDescription)
+	}
+	if response.StatusCode != http.StatusOK || answer.AccessToken == "" {
+		return nil, fmt.Errorf("oauth: %s", response.Status)
+	}
+
+	s.token = &OAuthToken{AccessToken: answer.AccessToken}
+	if answer.ExpiresIn > 0 {
+		s.token.Expiry = time.Now().Add(time.Duration(answer.ExpiresIn) * time.Second)
+	}
+	return s.token, nil
+}
+`
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// docSpan is a piece of text in the reference, linking to Link if set.
+type docSpan struct {
+	Text string
+	Link string
+}
+
+type docCell []docSpan
+
+func docText(s string) docCell {
+	if s == "" {
+		return nil
+	}
+	return docCell{{Text: s}}
+}
+
+// docWriter renders the reference as Markdown or HTML.
+type docWriter interface {
+	Heading(level int, title string)
+	Paragraph(cell docCell)
+	Table(header []string, rows []docRow)
+	Bytes() []byte
+}
+
+type docRow []docCell
+
+// anchor returns the id of the heading with title, as GitHub derives it.
+func anchor(title string) string {
+	return strings.ToLower(strings.Replace(title, " ", "-", -1))
+}
+
+type markdownDoc struct{ b buffer }
+
+func (d *markdownDoc) Heading(level int, title string) {
+	d.b.Sprintf("%s %s\n\n", strings.Repeat("#", level), title)
+}
+
+func (d *markdownDoc) Paragraph(cell docCell) {
+	d.b.Sprintf("%s\n\n", d.cell(cell, false))
+}
+
+func (d *markdownDoc) Table(header []string, rows []docRow) {
+	d.b.Sprintf("| %s |\n|%s\n", strings.Join(header, " | "), strings.Repeat(" --- |", len(header)))
+	for _, row := range rows {
+		var cells []string
+		for _, cell := range row {
+			cells = append(cells, d.cell(cell, true))
+		}
+		d.b.Sprintf("| %s |\n", strings.Join(cells, " | "))
+	}
+	d.b.Sprintf("\n")
+}
+
+func (d *markdownDoc) cell(cell docCell, inTable bool) string {
+	var parts []string
+	for _, span := range cell {
+		s := strings.NewReplacer("\\", "\\\\", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]", "<", "&lt;").Replace(span.Text)
+		if inTable {
+			s = strings.Replace(s, "|", "\\|", -1)
+		}
+		if span.Link != "" {
+			s = "[" + s + "](" + span.Link + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "")
+}
+
+func (d *markdownDoc) Bytes() []byte {
+	return d.b.Bytes()
+}
+
+type htmlDoc struct{ b buffer }
+
+func (d *htmlDoc) Heading(level int, title string) {
+	d.b.Sprintf("<h%d id=\"%s\">%s</h%[1]d>\n", level, html.EscapeString(anchor(title)), html.EscapeString(title))
+}
+
+func (d *htmlDoc) Paragraph(cell docCell) {
+	d.b.Sprintf("<p>%s</p>\n", d.cell(cell))
+}
+
+func (d *htmlDoc) Table(header []string, rows []docRow) {
+	d.b.Sprintf("<table>\n<tr>")
+	for _, h := range header {
+		d.b.Sprintf("<th>%s</th>", html.EscapeString(h))
+	}
+	d.b.Sprintf("</tr>\n")
+	for _, row := range rows {
+		d.b.Sprintf("<tr>")
+		for _, cell := range row {
+			d.b.Sprintf("<td>%s</td>", d.cell(cell))
+		}
+		d.b.Sprintf("</tr>\n")
+	}
+	d.b.Sprintf("</table>\n")
+}
+
+func (d *htmlDoc) cell(cell docCell) string {
+	var parts []string
+	for _, span := range cell {
+		s := html.EscapeString(span.Text)
+		if span.Link != "" {
+			s = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(span.Link), s)
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "")
+}
+
+func (d *htmlDoc) Bytes() []byte {
+	b := NewBuffer()
+	b.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s reference</title>\n", html.EscapeString(profile.Title))
+	b.Sprintf("<style>table{border-collapse:collapse}th,td{border:1px solid #ccc;padding:4px;vertical-align:top;text-align:left}</style>\n")
+	b.Sprintf("</head>\n<body>\n%s</body>\n</html>\n", d.b.String())
+	return b.Bytes()
+}
+
+// docTree walks the request or response type of a call, writing a row per
+// field. Types already on the path are not walked again.
+type docTree struct {
+	callName string
+	request  bool
+	rows     []docRow
+	codes    map[string]bool
+	walking  map[string]bool
+}
+
+func (t *docTree) walk(c *complexType, path string) {
+	if t.walking[c.GetName()] {
+		return
+	}
+	t.walking[c.GetName()] = true
+	defer delete(t.walking, c.GetName())
+
+	for _, x := range c.GetElements() {
+		switch f := x.(type) {
+		case element:
+			if !t.included(f.Annotation) {
+				continue
+			}
+			fieldPath := joinPath(path, f.GetName())
+			t.rows = append(t.rows, t.elementRow(f, fieldPath))
+			if sub, ok := FindComplex(f.GetType().String()); ok && !f.GetType().IsXS() {
+				t.walk(sub, fieldPath)
+			}
+		case attribute:
+			if !t.included(f.Annotation) {
+				continue
+			}
+			t.rows = append(t.rows, t.attributeRow(f, path+"@"+f.GetName()))
+		}
+	}
+}
+
+// included reports whether the call uses a field. Fields without CallInfo are
+// listed for every call.
+func (t *docTree) included(a *annotation) bool {
+	if a == nil || len(a.AppInfo.CallInfo) == 0 {
+		return true
+	}
+	return appInfoExt.IncludedIn(a, t.callName, t.request)
+}
+
+func (t *docTree) elementRow(e element, fieldPath string) docRow {
+	details := e.TypeDetails()
+
+	min := 0
+	if e.MinOccurs != "" {
+		min, _ = strconv.Atoi(e.MinOccurs)
+	}
+	if t.request {
+		min, _ = e.MinLen(t.callName)
+	}
+	max := "1"
+	if n, ok := e.SliceLen(); ok {
+		max = "*"
+		if n > 0 {
+			max = strconv.Itoa(n)
+		}
+	}
+	rules, _ := appInfoExt.ValidationRules(e.Annotation, t.callName)
+	if rule, ok := docRule(rules, ValTypMaxOccurs); ok && details.IsSlice {
+		max = fmt.Sprint(rule.Value)
+	}
+	occurs := fmt.Sprintf("%d..%s", min, max)
+	if strconv.Itoa(min) == max {
+		occurs = max
+	}
+	return t.row(fieldPath, t.typeName(e.GetType(), details), occurs, e.Annotation, rules, details.Enum, "")
+}
+
+func (t *docTree) attributeRow(a attribute, fieldPath string) docRow {
+	occurs := "0..1"
+	if a.Use == "required" {
+		occurs = "1"
+	}
+	rules, _ := appInfoExt.ValidationRules(a.Annotation, t.callName)
+	return t.row(fieldPath, t.typeName(a.GetType(), a.TypeDetails()), occurs, a.Annotation, rules, a.TypeDetails().Enum, a.Fixed)
+}
+
+// typeName returns the schema type of a field, the CodeType for fields based
+// on one and the value type of simple content types, e.g. "AmountType (double)".
+func (t *docTree) typeName(x Type, details *TypeDetails) string {
+	if details.Enum != "" {
+		return details.Enum
+	}
+	if c, ok := FindComplex(x.String()); ok && !x.IsXS() && c.SimpleContent != nil && c.SimpleContent.Extension != nil {
+		return fmt.Sprintf("%s (%s)", x.String(), c.SimpleContent.Extension.GetType().String())
+	}
+	return x.String()
+}
+
+func (t *docTree) row(fieldPath, typeName, occurs string, a *annotation, rules ValidationContainer, enum, fixed string) docRow {
+	var use, maxLength string
+	if t.request {
+		use = appInfoExt.RequiredInputFor(a, t.callName)
+	} else {
+		use = appInfoExt.ReturnedFor(a, t.callName)
+	}
+	if rule, ok := docRule(rules, ValTypMaxLength); ok {
+		maxLength = fmt.Sprint(rule.Value)
+	}
+
+	var values docCell
+	if rule, ok := docRule(rules, ValTypOnlyTheseValues); ok {
+		values = docText(strings.Join(splitValues(rule.Value), ", "))
+	} else if enum != "" {
+		t.codes[enum] = true
+		values = docCell{{Text: enum, Link: "#" + anchor(enum)}}
+		if rule, ok := docRule(rules, ValTypAllValuesExcept); ok {
+			values = append(values, docSpan{Text: " except " + strings.Join(splitValues(rule.Value), ", ")})
+		}
+	}
+	if fixed != "" {
+		values = docText("fixed: " + fixed)
+	}
+
+	return docRow{docText(fieldPath), docText(typeName), docText(occurs), docText(use), docText(maxLength), values, t.notes(a, rules)}
+}
+
+// notes returns the description of a field followed by its default, limits,
+// deprecation and SeeLinks.
+func (t *docTree) notes(a *annotation, rules ValidationContainer) (cell docCell) {
+	var sentences []string
+	if d := description(a); d != "" {
+		sentences = append(sentences, d)
+	}
+	if t.request {
+		if v, ok := appInfoExt.DefaultFor(a, t.callName); ok {
+			sentences = append(sentences, "Default: "+v+".")
+		}
+	}
+	if rule, ok := docRule(rules, ValTypMin); ok {
+		sentences = append(sentences, fmt.Sprintf("Min: %v.", rule.Value))
+	}
+	if rule, ok := docRule(rules, ValTypMax); ok {
+		sentences = append(sentences, fmt.Sprintf("Max: %v.", rule.Value))
+	}
+
+	var info appInfo
+	if a != nil {
+		info = a.AppInfo
+	}
+	if info.DeprecationVersion != 0 {
+		sentences = append(sentences, fmt.Sprintf("Deprecated in version %d.", info.DeprecationVersion))
+		if info.EndOfLifeVersion != 0 {
+			sentences = append(sentences, fmt.Sprintf("End of life in version %d.", info.EndOfLifeVersion))
+		}
+		if d := strings.Join(strings.Fields(info.DeprecationDetails), " "); d != "" {
+			sentences = append(sentences, strings.TrimSuffix(d, ".")+".")
+		}
+		if info.UseInstead != nil && *info.UseInstead != "" {
+			sentences = append(sentences, "Use "+*info.UseInstead+" instead.")
+		}
+	}
+	cell = docText(strings.Join(sentences, " "))
+
+	links := info.SeeLink
+	for _, ci := range info.CallInfo {
+		if ci.SeeLink.URL != "" && (ci.AllCalls != nil || contains(ci.CallName, t.callName)) {
+			links = append(links, ci.SeeLink)
+		}
+	}
+	first := true
+	for _, link := range links {
+		if link.URL == "" {
+			continue
+		}
+		title := strings.Join(strings.Fields(link.Title), " ")
+		if title == "" {
+			title = link.URL
+		}
+		sep := ", "
+		if first {
+			sep, first = "See: ", false
+			if len(cell) > 0 {
+				sep = " See: "
+			}
+		}
+		cell = append(cell, docSpan{Text: sep}, docSpan{Text: title, Link: link.URL})
+	}
+	return cell
+}
+
+// docRule returns the last rule of type v, the per-call rule when both
+// general and per-call rules are given.
+func docRule(rules ValidationContainer, v ValidationType) (rule *ValidationRule, ok bool) {
+	for i := range rules {
+		if rules[i].Type == v {
+			rule, ok = &rules[i], true
+		}
+	}
+	return
+}
+
+func splitValues(value interface{}) []string {
+	parts := strings.Split(fmt.Sprint(value), ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// writeDocs writes a reference of the exported calls to -docs: the fields of
+// each call's request and response with their type, cardinality, use, limits
+// and notes, then the values of the CodeTypes used. Files ending in .html
+// are written as HTML, others as Markdown.
+func writeDocs() {
+	if *docsFile == "" {
+		return
+	}
+	var d docWriter = &markdownDoc{b: NewBuffer()}
+	if ext := strings.ToLower(filepath.Ext(*docsFile)); ext == ".html" || ext == ".htm" {
+		d = &htmlDoc{b: NewBuffer()}
+	}
+
+	calls := append([]string{}, exportedElements...)
+	sort.Strings(calls)
+	codes := map[string]bool{}
+
+	d.Heading(1, profile.Title+" reference")
+	d.Paragraph(docText(fmt.Sprintf("API version %s.", *apiVersion)))
+	var index docCell
+	for i, call := range calls {
+		if i > 0 {
+			index = append(index, docSpan{Text: " · "})
+		}
+		index = append(index, docSpan{Text: call, Link: "#" + anchor(call)})
+	}
+	d.Paragraph(index)
+
+	for _, call := range calls {
+		d.Heading(2, call)
+		for _, kind := range []string{"Request", "Response"} {
+			c, ok := FindComplex(call + kind + "Type")
+			if !ok {
+				continue
+			}
+			if kind == "Request" {
+				if desc := description(&c.Annotation); desc != "" {
+					d.Paragraph(docText(desc))
+				}
+			}
+			tree := &docTree{callName: call, request: kind == "Request", codes: codes, walking: map[string]bool{}}
+			tree.walk(c, "")
+
+			use := "Required"
+			if !tree.request {
+				use = "Returned"
+			}
+			d.Heading(3, call+" "+kind)
+			if len(tree.rows) == 0 {
+				d.Paragraph(docText("No fields."))
+				continue
+			}
+			d.Table([]string{"Field", "Type", "Occurs", use, "Max length", "Values", "Notes"}, tree.rows)
+		}
+	}
+
+	if len(codes) > 0 {
+		var names []string
+		for name := range codes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		d.Heading(2, "Code types")
+		for _, name := range names {
+			x, ok := FindSimple(name)
+			if !ok {
+				continue
+			}
+			d.Heading(3, name)
+			if desc := description(&x.Annotation); desc != "" {
+				d.Paragraph(docText(desc))
+			}
+			values := x.Values()
+			var rows []docRow
+			for _, e := range x.Restriction.Enumeration {
+				if contains(values, e.Value) {
+					rows = append(rows, docRow{docText(e.Value), docText(description(&e.Annotation))})
+				}
+			}
+			d.Table([]string{"Value", "Notes"}, rows)
+		}
+	}
+
+	if err := ioutil.WriteFile(*docsFile, d.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
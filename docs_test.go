@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_writeDocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator")
+	}
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"ebay.md", []string{
+			"## AddItem\n",
+			"### AddItem Request\n",
+			"| Item.Title | string | 0..1 | Yes | 80 |  | Name of the item as it appears in the listing. See: [Item titles](https://example.com/titles) |\n",
+			"| Item.Location | string | 0..1 | No |",
+			"| Item.Currency | CurrencyCodeType | 0..1 | Yes |  | [CurrencyCodeType](#currencycodetype) |",
+			"### CurrencyCodeType\n",
+		}},
+		{"ebay.html", []string{
+			`<title>eBay Trading API reference</title>`,
+			`<h2 id="additem">AddItem</h2>`,
+			`<tr><td>Item.Title</td><td>string</td><td>0..1</td><td>Yes</td><td>80</td>`,
+			`<tr><td>Item.Location</td><td>string</td><td>0..1</td><td>No</td>`,
+			`<td><a href="#currencycodetype">CurrencyCodeType</a></td>`,
+			`<h3 id="currencycodetype">CurrencyCodeType</h3>`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := t.TempDir()
+			generate(t, dir, "-i", "testdata/mini.xsd", "-o", "ebaysvc.go", "-docs", tt.file)
+			data, err := ioutil.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("no %q in\n%s", want, data)
+				}
+			}
+		})
+	}
+}
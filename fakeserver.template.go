@@ -0,0 +1,92 @@
+package main
+
+// In-process fake gateway. Emitted with -fake-server.
+var templateFakeServer = `
+// FakeServer is an httptest based stand-in for the eBay API gateway. Point
+// APIGateway at FakeServer.URL and register a handler for every call the
+// test makes:
+//
+//	fake := NewFakeServer()
+//	defer fake.Close()
+//	APIGateway = fake.URL
+//	fake.HandleAddItem(func(r *AddItemRequestType) (*AddItemResponseType, error) { ... })
+//
+// Requests with missing headers, requests failing Validate() and calls
+// without a handler are answered with an eBay style Failure response.
+type FakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]fakeHandler
+}
+
+type fakeHandler func(body io.Reader) (interface{}, error)
+
+// FakeError lets a handler choose the error code of the Failure response.
+type FakeError struct {
+	Code    string
+	Message string
+}
+
+func (e *FakeError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{handlers: make(map[string]fakeHandler)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *FakeServer) handle(callName string, h fakeHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[callName] = h
+}
+
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	callName := r.Header.Get(callNameHeader)
+	for _, header := range requiredHeaders {
+		if r.Header.Get(header) == "" {
+			s.fail(w, callName, &FakeError{Code: "MissingHeader", Message: "header " + header + " is not set"})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	h, ok := s.handlers[callName]
+	s.mu.Unlock()
+	if !ok {
+		s.fail(w, callName, &FakeError{Code: "NoHandler", Message: "no handler registered for " + callName})
+		return
+	}
+
+	response, err := h(r.Body)
+	if err != nil {
+		s.fail(w, callName, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).EncodeElement(response, xml.StartElement{
+		Name: xml.Name{Space: xmlNamespace, Local: callName + "Response"},
+	})
+}
+
+func (s *FakeServer) fail(w http.ResponseWriter, callName string, err error) {
+	fakeErr, ok := err.(*FakeError)
+	if !ok {
+		fakeErr = &FakeError{Code: "HandlerError", Message: err.Error()}
+	}
+	code, message := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	xml.EscapeText(code, []byte(fakeErr.Code))
+	xml.EscapeText(message, []byte(fakeErr.Message))
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, "%s<%sResponse xmlns=\"%s\"><Ack>Failure</Ack><Errors>"+
+		"<ShortMessage>%s</ShortMessage><LongMessage>%[4]s</LongMessage><ErrorCode>%s</ErrorCode>"+
+		"<SeverityCode>Error</SeverityCode><ErrorClassification>RequestError</ErrorClassification>"+
+		"</Errors></%[2]sResponse>", xml.Header, callName, xmlNamespace, message, code)
+}
+`
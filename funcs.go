@@ -1,32 +1,77 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func requester(typeName string) string {
-	return fmt.Sprintf(`func (x *%[1]sRequestType) Request(eBayAuthToken, siteID string) (response %[1]sResponseType, err error) {
-		if x.RequesterCredentials == nil {
-			x.RequesterCredentials = &XMLRequesterCredentialsType{}
+	params, args, credentials := requestParams()
+	do := fmt.Sprintf(`func (x *%[1]sRequestType) do(req *xbayRequester) error {`, typeName)
+	if profile.Credentials {
+		do = fmt.Sprintf(`func (x *%[1]sRequestType) do(req *xbayRequester, credentials Credentials) error {
+		if credentials == nil {
+			return ErrCredentialsNotSet
 		}
-		x.RequesterCredentials.EBayAuthToken.Set(eBayAuthToken)
-		
+		token, err := credentials.authorize(req)
+		if err != nil {
+			return err
+		}
+
+		// The token is set on a copy, the caller's request is left as it is.
+		request := *x
+		requesterCredentials := XMLRequesterCredentialsType{}
+		if x.RequesterCredentials != nil {
+			requesterCredentials = *x.RequesterCredentials
+		}
+		if token == "" {
+			requesterCredentials.EBayAuthToken = NullString{}
+		} else {
+			requesterCredentials.EBayAuthToken.Set(token)
+		}
+		request.RequesterCredentials = &requesterCredentials
+		if reflect.ValueOf(requesterCredentials).IsZero() {
+			request.RequesterCredentials = nil
+		}
+		x = &request
+		`, typeName)
+		Imports["reflect"] = true
+	}
+
+	return fmt.Sprintf(`func (x *%[1]sRequestType) Request(%[3]s) (response %[1]sResponseType, err error) {
+		return x.RequestContext(context.Background(), %[4]s)
+	}
+
+	func (x *%[1]sRequestType) RequestContext(ctx context.Context, %[3]s) (response %[1]sResponseType, err error) {
+		err = x.do(newRequester(ctx, %[6]q, siteID, &response)%[5]s)
+		%[2]s
+		return
+	}
+
+	%[7]s
 		if RequestValidation {
-			if err = x.Validate(); err != nil {
-				return
+			if err := x.Validate(); err != nil {
+				return err
 			}
 		}
 		
-		req := newRequester("%[1]s", siteID, &response)
-		if err = xml.NewEncoder(req.body).Encode(x); err != nil {
-			return
-		}
-	
-		if err = req.request(); err != nil {
-			return
+		if err := xml.NewEncoder(req.body).Encode(x); err != nil {
+			return err
 		}
-	
-		return
+		return req.request()
 	}
-	`, typeName)
+	`, typeName, validateResponse(), params, args, credentials, operationName(typeName), do)
+}
+
+// validateResponse returns the check of a decoded response against its
+// Validate() method. Empty unless generated with -validate-response.
+func validateResponse() string {
+	if !*validateResponses {
+		return ""
+	}
+	return `if err == nil && ResponseValidation {
+			err = response.Validate()
+		}`
 }
 
 func xmlEncoder(typeName string) string {
@@ -55,8 +100,250 @@ func xmlMarshaler(typeName string) string {
 
 func validator(typeName, body string) string {
 	return fmt.Sprintf(`func (x %[1]sRequestType) Validate() error {
+		var errs ValidationErrors
 		%[2]s
+		if len(errs) > 0 {
+			return errs
+		}
 		return nil
 	}
 	`, typeName, body)
 }
+
+func defaulter(typeName, body string) string {
+	return fmt.Sprintf(`// ApplyDefaults sets unset fields of x to the defaults documented for %[1]s
+	// and fixed attributes to their fixed value. Nested types are only filled in
+	// when they are not nil.
+	func (x *%[1]sRequestType) ApplyDefaults() {
+		%[2]s
+	}
+	`, typeName, body)
+}
+
+// constructor returns New<Call>Request, creating the nested types required by
+// the call before applying the defaults.
+func constructor(typeName string) string {
+	request, ok := FindComplex(typeName + "RequestType")
+	if !ok {
+		return ""
+	}
+	b := NewBuffer()
+	requiredTypes(typeName, request, "x", b, map[string]bool{})
+
+	return fmt.Sprintf(`// New%[1]sRequest returns a %[1]s request with the types required by the
+	// call created and documented defaults applied.
+	func New%[1]sRequest() *%[1]sRequestType {
+		x := &%[1]sRequestType{}
+		%[2]s
+		x.ApplyDefaults()
+		return x
+	}
+	`, typeName, b.String())
+}
+
+func requiredTypes(callName string, c *complexType, path string, b buffer, seen map[string]bool) {
+	if seen[c.Name] {
+		return
+	}
+	seen[c.Name] = true
+	defer delete(seen, c.Name)
+
+	for _, x := range c.GetElements() {
+		e, ok := x.(element)
+		if !ok || !appInfoExt.RequiredFor(e.Annotation, callName) {
+			continue
+		}
+		if !e.TypeDetails().IsPointer {
+			continue
+		}
+		child, ok := FindComplex(e.GetType().String())
+		if !ok {
+			continue
+		}
+		field := path + "." + UpperFirstLetter(e.GetName())
+		b.Sprintf("%s = &%s{}\r\n", field, child.GetType().GoType())
+		requiredTypes(callName, child, field, b, seen)
+	}
+}
+
+func responseValidator(typeName, body string) string {
+	return fmt.Sprintf(`func (x %[1]sResponseType) Validate() error {
+		var errs ValidationErrors
+		%[2]s
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	}
+	`, typeName, body)
+}
+
+func fakeHandler(typeName string) string {
+	return fmt.Sprintf(`// Handle%[1]s registers the handler answering %[1]s calls.
+	func (s *FakeServer) Handle%[1]s(h func(*%[1]sRequestType) (*%[1]sResponseType, error)) {
+		s.handle(%[2]q, func(body io.Reader) (interface{}, error) {
+			request := &%[1]sRequestType{}
+			if err := xml.NewDecoder(body).Decode(request); err != nil {
+				return nil, &FakeError{Code: "InvalidRequest", Message: err.Error()}
+			}
+			if v, ok := interface{}(request).(interface{ Validate() error }); ok {
+				if err := v.Validate(); err != nil {
+					return nil, &FakeError{Code: "InvalidRequest", Message: err.Error()}
+				}
+			}
+			response, err := h(request)
+			if err != nil {
+				return nil, err
+			}
+			if response == nil {
+				response = &%[1]sResponseType{}
+			}
+			return response, nil
+		})
+	}
+	`, typeName, operationName(typeName))
+}
+
+// paginator returns an iterator over all result pages for calls whose request
+// takes Pagination and whose response reports PaginationResult or HasMore*.
+func paginator(typeName string) string {
+	hasMore, ok := paginated(typeName)
+	if !ok {
+		return ""
+	}
+	Imports["iter"] = true
+	params, args, _ := requestParams()
+
+	return fmt.Sprintf(`// All requests every page of %[1]s, starting at x.Pagination.PageNumber (default: 1),
+	// and yields each response. Iteration stops after the last page, on the first error
+	// or when the loop is left early.
+	func (x *%[1]sRequestType) All(ctx context.Context, %[3]s) iter.Seq2[*%[1]sResponseType, error] {
+		return func(yield func(*%[1]sResponseType, error) bool) {
+			request := *x
+			pagination := PaginationType{}
+			if x.Pagination != nil {
+				pagination = *x.Pagination
+			}
+			if !pagination.PageNumber.Valid || pagination.PageNumber.Value() < 1 {
+				pagination.PageNumber.Set(1)
+			}
+			request.Pagination = &pagination
+
+			for {
+				response, err := request.RequestContext(ctx, %[4]s)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(&response, nil) {
+					return
+				}
+				if !(%[2]s) {
+					return
+				}
+				pagination.PageNumber.Set(pagination.PageNumber.Value() + 1)
+			}
+		}
+	}
+	`, typeName, hasMore, params, args)
+}
+
+// paginated reports whether a call can be paged through and returns the
+// condition telling if another page follows the current response.
+func paginated(typeName string) (string, bool) {
+	request, ok := FindComplex(typeName + "RequestType")
+	if !ok {
+		return "", false
+	}
+	response, ok := FindComplex(typeName + "ResponseType")
+	if !ok {
+		return "", false
+	}
+
+	hasPagination := false
+	for _, e := range request.GetElements() {
+		if e.GetName() == "Pagination" && e.GetType().String() == "PaginationType" {
+			hasPagination = true
+		}
+	}
+	if !hasPagination {
+		return "", false
+	}
+
+	for _, e := range response.GetElements() {
+		if strings.HasPrefix(e.GetName(), "HasMore") && e.GetType().GoType() == "NullBool" {
+			return fmt.Sprintf("response.%s.Value()", UpperFirstLetter(e.GetName())), true
+		}
+	}
+	for _, e := range response.GetElements() {
+		if e.GetName() == "PaginationResult" && e.GetType().String() == "PaginationResultType" {
+			return "response.PaginationResult != nil && pagination.PageNumber.Value() < response.PaginationResult.TotalNumberOfPages.Value()", true
+		}
+	}
+	return "", false
+}
+
+// streamers returns streaming variants of a call for every repeated element
+// its response wraps in an *Array element (ItemArray.Item, OrderArray.Order, ...).
+func streamers(typeName string) string {
+	response, ok := FindComplex(typeName + "ResponseType")
+	if !ok {
+		return ""
+	}
+
+	params, _, credentials := requestParams()
+	b := NewBuffer()
+	names := map[string]bool{}
+	for _, x := range response.GetElements() {
+		wrapper, ok := x.(element)
+		if !ok || !strings.HasSuffix(wrapper.GetName(), "Array") {
+			continue
+		}
+		if _, yes := wrapper.SliceLen(); yes {
+			continue
+		}
+		wrapperType, ok := FindComplex(wrapper.GetType().String())
+		if !ok {
+			continue
+		}
+		for _, y := range wrapperType.GetElements() {
+			child, ok := y.(element)
+			if !ok || !child.GetType().IsComplexType() {
+				continue
+			}
+			if _, yes := child.SliceLen(); !yes {
+				continue
+			}
+			name := UpperFirstLetter(child.GetName())
+			if names[name] {
+				name = UpperFirstLetter(wrapper.GetName()) + name
+			}
+			names[name] = true
+
+			b.Sprintf(`// Stream%[2]s sends a %[1]s call and hands every %[3]s.%[4]s element of the
+			// response to fn as soon as it is decoded. The returned response holds the rest
+			// of the document (Ack, Errors, pagination, ...) with %[3]s left empty.
+			func (x *%[1]sRequestType) Stream%[2]s(ctx context.Context, %[6]s, fn func(*%[5]s) error) (response %[1]sResponseType, err error) {
+				req := newRequester(ctx, %[8]q, siteID, &response)
+				req.decode = func(r io.Reader) error {
+					return Decode%[1]sResponse%[2]s(r, &response, fn)
+				}
+				err = x.do(req%[7]s)
+				return
+			}
+
+			// Decode%[1]sResponse%[2]s reads a %[1]s response from r the way Stream%[2]s does.
+			func Decode%[1]sResponse%[2]s(r io.Reader, response *%[1]sResponseType, fn func(*%[5]s) error) error {
+				return decodeStream(r, []string{"%[3]s", "%[4]s"}, response, func(dec *xml.Decoder, start *xml.StartElement) error {
+					v := &%[5]s{}
+					if err := dec.DecodeElement(v, start); err != nil {
+						return err
+					}
+					return fn(v)
+				})
+			}
+			`, typeName, name, wrapper.GetName(), child.GetName(), child.GetType().GoType(), params, credentials, operationName(typeName))
+		}
+	}
+	return b.String()
+}
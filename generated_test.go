@@ -0,0 +1,101 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generatedPackages are generated from the schemas in testdata for the tests
+// in testdata/<dir>, which run against them with go test. Output files of the
+// flags are written to the package directory, flag values starting with
+// testdata/ name input files.
+var generatedPackages = []struct {
+	dir    string
+	schema string
+	flags  []string
+}{
+	{"trading", "mini.xsd", []string{"-recorder", "-fake-server", "-validate-response", "-jsonschema", "schemas", "-rules", "testdata/trading.rules.json", "-rules-out", "rules.json", "-openapi", "openapi.json"}},
+	{"soap", "mini.wsdl", []string{"-soap"}},
+	{"finding", "finding.xsd", []string{"-api", "finding", "-fake-server"}},
+	{"notifications", "notifications.xsd", []string{"-notifications"}},
+	{"bulk", "mini.xsd", []string{"-bulk"}},
+	{"auth", "auth.xsd", []string{"-auth-go", "auth.go"}},
+}
+
+// generateEnv makes the test binary run the generator, see
+// Test_generatedPackages.
+const generateEnv = "XSDBAY_TEST_GENERATE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(generateEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// generate runs the generator in dir. Arguments starting with testdata/ are
+// made absolute, other output files are written to dir.
+func generate(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "testdata/") {
+			abs, err := filepath.Abs(arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args[i] = abs
+		}
+	}
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), generateEnv+"=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generating %v: %s\n%s", args, err, out)
+	}
+}
+
+func Test_generatedPackages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates and tests packages")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+
+	for _, p := range generatedPackages {
+		t.Run(p.dir, func(t *testing.T) {
+			dir := t.TempDir()
+			generate(t, dir, append([]string{"-i", "testdata/" + p.schema, "-o", "ebaysvc.go"}, p.flags...)...)
+
+			if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module ebaysvc\n\ngo 1.23\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tests, err := filepath.Glob(filepath.Join("testdata", p.dir, "*_test.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, test := range tests {
+				data, err := ioutil.ReadFile(test)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(test)), data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			run := exec.Command(goTool, "test", ".")
+			run.Dir = dir
+			run.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+			if out, err := run.CombinedOutput(); err != nil {
+				t.Fatalf("go test: %s\n%s", err, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// jsonSchema builds JSON Schemas of the JSON encoding of the generated types,
+// walking complexType.GetElements like complexType.Generate does. Rules are
+// taken from RuleSet, so they match the generated Validate() methods.
+type jsonSchema struct {
+	// callName is the RuleSet key: <Call> or <Call>Response.
+	callName string
+	// refPrefix is prepended to type names in $ref, e.g. "#/$defs/".
+	refPrefix string
+	defs      map[string]interface{}
+	// signatures holds the rules below the paths of each definition, see
+	// defName.
+	signatures map[string]string
+}
+
+type object map[string]interface{}
+
+func newJSONSchema(callName, refPrefix string, defs map[string]interface{}) *jsonSchema {
+	return &jsonSchema{callName: callName, refPrefix: refPrefix, defs: defs, signatures: map[string]string{}}
+}
+
+// Ref defines the complex type in defs and returns a reference to it.
+func (s *jsonSchema) Ref(c *complexType, path string) object {
+	name := s.defName(c.GetName(), path)
+	ref := object{"$ref": s.refPrefix + name}
+	if _, ok := s.defs[name]; ok {
+		return ref
+	}
+	s.defs[name] = nil
+
+	properties := object{}
+	var required []string
+	for _, x := range c.GetElements() {
+		details, a, ok := fieldDetails(x)
+		if !ok {
+			continue
+		}
+		name := ToSnake(x.GetName())
+		fieldPath := joinPath(path, UpperFirstLetter(details.Field))
+
+		property := s.field(details, fieldPath)
+		if d := description(a); d != "" {
+			property["description"] = d
+		}
+		if f, ok := x.(attribute); ok && f.Fixed != "" {
+			property["const"] = f.Fixed
+		}
+		if s.has(fieldPath, ValTypRequired, ValTypReturned) {
+			required = append(required, name)
+		}
+		properties[name] = property
+	}
+
+	def := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		def["required"] = required
+	}
+	if d := description(&c.Annotation); d != "" {
+		def["description"] = d
+	}
+	s.defs[name] = def
+	return ref
+}
+
+// defName returns the name of the definition of the type used at path. A
+// type used at paths with different rules below them (Item.StartPrice,
+// Item.BuyItNowPrice) gets a definition for each: AmountType, AmountType_2.
+func (s *jsonSchema) defName(typeName, path string) string {
+	prefix := path + "."
+	below := map[string][]ExportedRule{}
+	for fieldPath, rules := range RuleSet[s.callName] {
+		if path == "" || strings.HasPrefix(fieldPath, prefix) {
+			below[strings.TrimPrefix(fieldPath, prefix)] = rules
+		}
+	}
+	data, err := json.Marshal(below)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for i := 1; ; i++ {
+		name := typeName
+		if i > 1 {
+			name = fmt.Sprintf("%s_%d", typeName, i)
+		}
+		signature, ok := s.signatures[name]
+		if !ok {
+			s.signatures[name] = string(data)
+			return name
+		}
+		if signature == string(data) {
+			return name
+		}
+	}
+}
+
+func (s *jsonSchema) field(t *TypeDetails, fieldPath string) object {
+	if t.IsSlice {
+		item := *t
+		item.IsSlice = false
+		items := s.value(&item, fieldPath+"[]")
+		s.rules(items, fieldPath+"[]")
+		array := object{"type": "array", "items": items}
+		s.rules(array, fieldPath)
+		return array
+	}
+	value := s.value(t, fieldPath)
+	s.rules(value, fieldPath)
+	return value
+}
+
+func (s *jsonSchema) value(t *TypeDetails, fieldPath string) object {
+	if c, ok := FindComplex(t.Type.String()); ok && !t.Type.IsXS() {
+		return s.Ref(c, fieldPath)
+	}
+	v := object{}
+	jsonType, nullable := t.JSONType()
+	switch jsonType {
+	case "":
+		log.Printf("JSON Schema: no JSON type for %s, leaving %s open", t.T(), fieldPath)
+		return v
+	case "base64":
+		v["type"] = "string"
+		v["contentEncoding"] = "base64"
+	default:
+		v["type"] = jsonType
+	}
+	if nullable && !s.has(fieldPath, ValTypRequired, ValTypReturned) {
+		v["type"] = []string{v["type"].(string), "null"}
+	}
+	if t.Enum != "" {
+		if x, ok := FindSimple(t.Enum); ok {
+			v["enum"] = x.Values()
+		}
+	}
+	return v
+}
+
+// rules adds the rules RuleSet holds for the field to its schema.
+func (s *jsonSchema) rules(v object, fieldPath string) {
+	for _, r := range RuleSet[s.callName][fieldPath] {
+		switch r.Type {
+		case ValTypMaxLength.String():
+			if contains(toStrings(v["type"]), "string") {
+				v["maxLength"] = r.Value
+			}
+		case ValTypMin.String():
+			v["minimum"] = r.Value
+		case ValTypMax.String():
+			v["maximum"] = r.Value
+		case ValTypMinOccurs.String():
+			v["minItems"] = r.Value
+		case ValTypMaxOccurs.String():
+			v["maxItems"] = r.Value
+		case ValTypOnlyTheseValues.String():
+			v["enum"] = r.Value
+		case ValTypAllValuesExcept.String():
+			v["not"] = object{"enum": r.Value}
+		case ValTypFixed.String():
+			v["const"] = r.Value
+		}
+	}
+}
+
+// has reports whether RuleSet holds one of the rule types for the field.
+func (s *jsonSchema) has(fieldPath string, types ...ValidationType) bool {
+	for _, r := range RuleSet[s.callName][fieldPath] {
+		for _, t := range types {
+			if r.Type == t.String() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldDetails returns the TypeDetails and annotation of a struct field
+// generated for x.
+func fieldDetails(x Xyer) (*TypeDetails, *annotation, bool) {
+	switch f := x.(type) {
+	case element:
+		return f.TypeDetails(), f.Annotation, true
+	case attribute:
+		return f.TypeDetails(), f.Annotation, true
+	case *extensionSimpleContent:
+		details := &TypeDetails{Field: f.GetName(), Type: f.GetType(), AliasFor: f.GetType()}
+		if base, ok := FindSimple(f.GetType().String()); ok {
+			details.SimpleType, details.AliasFor = true, base.GetType()
+		}
+		return details, nil, true
+	}
+	return nil, nil, false
+}
+
+func toStrings(v interface{}) []string {
+	switch x := v.(type) {
+	case string:
+		return []string{x}
+	case []string:
+		return x
+	}
+	return nil
+}
+
+// description returns the first documentation of an annotation with
+// whitespace collapsed.
+func description(a *annotation) string {
+	if a == nil || len(a.Documentation) == 0 {
+		return ""
+	}
+	return strings.Join(strings.Fields(a.Documentation[0].Contents), " ")
+}
+
+// writeJSONSchemas writes <Call>Request.schema.json and
+// <Call>Response.schema.json for every exported call to -jsonschema.
+func writeJSONSchemas() {
+	if *jsonSchemaDir == "" {
+		return
+	}
+	if err := os.MkdirAll(*jsonSchemaDir, 0755); err != nil {
+		log.Fatal(err)
+	}
+	for _, call := range exportedElements {
+		for _, kind := range []string{"Request", "Response"} {
+			c, ok := FindComplex(call + kind + "Type")
+			if !ok {
+				continue
+			}
+			ruleKey := call
+			if kind == "Response" {
+				ruleKey += kind
+			}
+			defs := map[string]interface{}{}
+			root := newJSONSchema(ruleKey, "#/$defs/", defs).Ref(c, "")
+			root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+			root["$id"] = call + kind + ".schema.json"
+			root["title"] = call + kind
+			root["$defs"] = defs
+
+			data, err := json.MarshalIndent(root, "", "  ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err = ioutil.WriteFile(filepath.Join(*jsonSchemaDir, call+kind+".schema.json"), data, 0644); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+// writeOpenAPI writes an OpenAPI 3.1 document to -openapi with one POST
+// operation per exported call, taking and returning the JSON encoding of the
+// call's request and response types. Component schemas are shared between
+// calls, so they describe the types only; per-call rules are in the
+// -jsonschema output.
+func writeOpenAPI() {
+	if *openAPIFile == "" {
+		return
+	}
+	schemas := map[string]interface{}{}
+	paths := object{}
+	builder := newJSONSchema("", "#/components/schemas/", schemas)
+	for _, call := range exportedElements {
+		request, ok := FindComplex(call + "RequestType")
+		if !ok {
+			continue
+		}
+		response, ok := FindComplex(call + "ResponseType")
+		if !ok {
+			continue
+		}
+
+		operation := object{
+			"operationId": call,
+			"requestBody": object{
+				"required": true,
+				"content":  object{"application/json": object{"schema": builder.Ref(request, "")}},
+			},
+			"responses": object{
+				"200": object{
+					"description": call + " response",
+					"content":     object{"application/json": object{"schema": builder.Ref(response, "")}},
+				},
+			},
+		}
+		if d := description(&request.Annotation); d != "" {
+			operation["description"] = d
+		}
+		paths["/"+call] = object{"post": operation}
+	}
+
+	document := object{
+		"openapi": "3.1.0",
+		"info": object{
+			"title":   profile.Title,
+			"version": *apiVersion,
+		},
+		"jsonSchemaDialect": "https://json-schema.org/draft/2020-12/schema",
+		"paths":             paths,
+		"components":        object{"schemas": schemas},
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile(*openAPIFile, data, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
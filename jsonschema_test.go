@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// A type used at paths with different rules gets a definition for each,
+// paths with the same rules share one.
+func Test_jsonSchema_Ref_pathRules(t *testing.T) {
+	loadSchema(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ns="urn:ebay:apis:eBLBaseComponents" targetNamespace="urn:ebay:apis:eBLBaseComponents" elementFormDefault="qualified">
+<xs:element name="AddItemRequest" type="ns:AddItemRequestType"/>
+<xs:complexType name="AddItemRequestType"><xs:sequence>
+ <xs:element name="Item" type="ns:ItemType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>Yes</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="ItemType"><xs:sequence>
+ <xs:element name="StartPrice" type="ns:AmountType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="BuyItNowPrice" type="ns:AmountType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="ReservePrice" type="ns:AmountType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="AmountType"><xs:simpleContent><xs:extension base="xs:double">
+ <xs:attribute name="currencyID" type="xs:string"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:attribute>
+</xs:extension></xs:simpleContent></xs:complexType>
+</xs:schema>`, "AddItem")
+	defer func() { RuleSet = map[string]map[string][]ExportedRule{} }()
+	RuleSet = map[string]map[string][]ExportedRule{"AddItem": {
+		"Item.StartPrice.CurrencyID":    {{Type: "Required"}},
+		"Item.StartPrice.Value":         {{Type: "Min", Value: 0.99}},
+		"Item.BuyItNowPrice.CurrencyID": {{Type: "Required"}},
+		"Item.ReservePrice.CurrencyID":  {{Type: "Required"}},
+	}}
+
+	request, _ := FindComplex("AddItemRequestType")
+	defs := map[string]interface{}{}
+	newJSONSchema("AddItem", "#/$defs/", defs).Ref(request, "")
+
+	item := defs["ItemType"].(object)["properties"].(object)
+	for field, want := range map[string]string{"start_price": "AmountType", "buy_it_now_price": "AmountType_2", "reserve_price": "AmountType_2"} {
+		if got := item[field].(object)["$ref"]; got != "#/$defs/"+want {
+			t.Errorf("%s $ref = %v, want %s", field, got, want)
+		}
+	}
+	if len(defs) != 4 {
+		t.Errorf("defs = %v, want AddItemRequestType, ItemType, AmountType and AmountType_2", reflect.ValueOf(defs).MapKeys())
+	}
+
+	value := func(def string) object {
+		return defs[def].(object)["properties"].(object)["value"].(object)
+	}
+	if got := value("AmountType")["minimum"]; got != 0.99 {
+		t.Errorf("AmountType value minimum = %v, want 0.99", got)
+	}
+	if got, ok := value("AmountType_2")["minimum"]; ok {
+		t.Errorf("AmountType_2 value minimum = %v, want none", got)
+	}
+	if got := defs["AmountType_2"].(object)["required"]; !reflect.DeepEqual(got, []string{"currency_id"}) {
+		t.Errorf("AmountType_2 required = %v, want [currency_id]", got)
+	}
+}
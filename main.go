@@ -13,6 +13,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,8 @@ type Xyer interface {
 	Generate()
 	Validator(callName, path string)
 	DeepValidator(callName, path string) bool
+	Defaulter(callName, path string)
+	DeepDefaulter(callName string) bool
 	Setter(typeName string)
 }
 
@@ -47,7 +50,32 @@ var (
 	apiVersion = flag.String("apiver", "", "API Version")
 	latestXSD  = flag.Bool("latest", false, "Download latest version")
 	cacheXSD   = flag.Bool("cache-xsd", false, "Cache downloaded file")
-	onlineXSD  = flag.String("download", "http://developer.ebay.com/webservices/latest/ebaysvc.xsd", "XSD link")
+	onlineXSD  = flag.String("download", "", "XSD link (Default: the latest schema of the -api)")
+
+	genRecorder   = flag.Bool("recorder", false, "Generate record-and-replay transport")
+	genFakeServer = flag.Bool("fake-server", false, "Generate in-process fake API server")
+	genSOAP       = flag.Bool("soap", false, "Generate a SOAP client of the WSDL operations")
+	genNotify     = flag.Bool("notifications", false, "Generate an http.Handler of eBay Platform Notifications")
+	genBulk       = flag.Bool("bulk", false, "Generate a writer and reader of bulk data exchange files")
+
+	validateResponses = flag.Bool("validate-response", false, "Generate Validate() for response types")
+	rulesFile         = flag.String("rules", "", "JSON or YAML (.yaml, .yml) file with additional validation rules")
+	rulesOutFile      = flag.String("rules-out", "", "Write the validation rules of every call as JSON to this file")
+	jsonSchemaDir     = flag.String("jsonschema", "", "Write JSON Schemas of every call's request and response to this directory")
+	openAPIFile       = flag.String("openapi", "", "Write an OpenAPI document of all calls as JSON to this file")
+	tsFile            = flag.String("ts", "", "Write TypeScript definitions of the generated types to this .d.ts file")
+	protoFile         = flag.String("proto", "", "Write Protocol Buffers definitions of the generated types to this .proto file")
+	protoPackage      = flag.String("proto-package", "ebay", "Package of the -proto definitions")
+	protoGoPackage    = flag.String("proto-go-package", "", "Go import path of the code protoc-gen-go generates from -proto")
+	protoGoFile       = flag.String("proto-go", "", "Write converters between the generated types and the -proto-go-package types to this Go file")
+	sqlFile           = flag.String("sql", "", "Write Postgres tables of the -sql-roots types to this .sql file")
+	sqlGoFile         = flag.String("sql-go", "", "Write functions inserting and reading the -sql tables to this Go file")
+	sqlRootTypes      = flag.String("sql-roots", "", "Response types stored by -sql, comma separated (Default: every call's response type)")
+	sqlDepth          = flag.Int("sql-depth", 4, "Deepest nested type stored by -sql")
+	authGoFile        = flag.String("auth-go", "", "Write helpers running the Auth'n'Auth consent flow and storing tokens to this Go file")
+	docsFile          = flag.String("docs", "", "Write a reference of the exported calls to this Markdown file, or HTML file if it ends in .html")
+	appInfoName       = flag.String("appinfo", "", "Dialect of the schema's appinfo annotations: ebay, soa, or generic for any XSD (Default: the -api profile's)")
+	apiName           = flag.String("api", "trading", "eBay API of the schema: trading, shopping, finding or merchandising")
 
 	onlineMask string = "http://developer.ebay.com/webservices/%d/ebaysvc.xsd"
 
@@ -63,6 +91,21 @@ var (
 	Enums     map[string]buffer = make(map[string]buffer)
 	Funcs     map[string]buffer = make(map[string]buffer)
 	Validator map[string]buffer = make(map[string]buffer)
+	Defaults  map[string]buffer = make(map[string]buffer)
+
+	// Packages imported by the generated file.
+	Imports map[string]bool = map[string]bool{
+		"bytes":         true,
+		"context":       true,
+		"database/sql":  true,
+		"encoding/json": true,
+		"encoding/xml":  true,
+		"errors":        true,
+		"io":            true,
+		"net/http":      true,
+		"strconv":       true,
+		"strings":       true,
+	}
 )
 
 type buffer struct {
@@ -93,6 +136,9 @@ func readInputFile() {
 			log.Fatal(err)
 		}
 		fileType = extXSD
+		if strings.HasSuffix(*onlineXSD, ".wsdl") {
+			fileType = extWSDL
+		}
 	} else {
 		_, file = path.Split(*inputFilePath)
 		log.Println("Reading file from ", *inputFilePath)
@@ -117,18 +163,33 @@ func readInputFile() {
 		log.Fatal(err)
 	}
 
+	switch fileType {
+	case extXSD:
+		xsdSc.normalise(xsdSc.Attrs)
+	case extWSDL:
+		wsdlSc.Types.Schema.normalise(append(wsdlSc.Attrs, wsdlSc.Types.Schema.Attrs...))
+	}
+	renameOperations(getSchema())
+
 	if fileType == extWSDL {
 		*apiVersion = wsdlSc.Service.Documentation.Version
 	}
 	if *apiVersion == "" && fileType == extXSD {
-		Vers := regexp.MustCompile(`<!-- Version (\d{4}) -->`).FindStringSubmatch(string(data[:80]))
+		head := data
+		if len(head) > 80 {
+			head = head[:80]
+		}
+		Vers := regexp.MustCompile(`<!-- Version (\d{4}) -->`).FindStringSubmatch(string(head))
 		if len(Vers) == 2 {
 			*apiVersion = Vers[1]
 			log.Printf("API Version: %s", *apiVersion)
 		}
 	}
 
-	if *apiVersion == "" {
+	// Schemas without calls are not versioned like the eBay API.
+	if *apiVersion == "" && appInfoExt.Calls(getSchema()) == nil {
+		*apiVersion = getSchema().Version
+	} else if *apiVersion == "" {
 		log.Fatalf("could identify API version. Use flag -apiver with right version number")
 	}
 
@@ -141,7 +202,10 @@ func main() {
 	start := time.Now()
 	flag.Parse()
 
+	loadProfile()
+	loadAppInfo()
 	readInputFile()
+	loadRules()
 
 	var filePath string = *outputFilePath
 	if filePath == "" {
@@ -153,10 +217,20 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if *exportElements == "" { //|| *checkMode != 0
+	withCalls := appInfoExt.Calls(getSchema()) != nil
+	if !withCalls {
+		callFlagsUnset()
+		var names []string
+		if *exportElements != "" {
+			names = strings.Split(strings.Replace(*exportElements, " ", "", -1), ",")
+		}
+		FromElements(names)
+	} else if *exportElements == "" { //|| *checkMode != 0
 		loadAllCalls()
 	} else {
-		exportedElements = strings.Split(strings.Replace(*exportElements, " ", "", -1), ",")
+		for _, name := range strings.Split(strings.Replace(*exportElements, " ", "", -1), ",") {
+			exportedElements = append(exportedElements, UpperFirstLetter(name))
+		}
 	}
 
 	for _, e := range exportedElements {
@@ -170,14 +244,21 @@ func main() {
 	// 	return
 	// }
 
-	fo := bytes.NewBufferString(fmt.Sprintf(templateEbaySVC, *apiVersion))
-	fo.WriteString(templateNulls)
+	// The SOAP client generates the header and fault types it uses.
+	var soap string
+	if *genSOAP {
+		soap = soapClient()
+	}
+
+	fo := bytes.NewBufferString(templateNulls)
 
-	fo.WriteString("type Request struct {\r\n")
-	for _, val := range exportedElements {
-		fo.WriteString(val + "Request " + val + "RequestType\r\n")
+	if len(exportedElements) > 0 {
+		fo.WriteString("type Request struct {\r\n")
+		for _, val := range exportedElements {
+			fo.WriteString(val + "Request " + val + "RequestType\r\n")
+		}
+		fo.WriteString("}\r\n\r\n")
 	}
-	fo.WriteString("}\r\n\r\n")
 
 	for _, v := range []map[string]buffer{Types, Calls, Enums, Funcs} {
 		for _, val := range v {
@@ -187,8 +268,16 @@ func main() {
 		}
 	}
 
+	hasStreamers := false
 	for k, val := range Validator {
+		if strings.HasSuffix(k, "Response") {
+			fo.WriteString(responseValidator(strings.TrimSuffix(k, "Response"), val.String()))
+			continue
+		}
 		if val.Len() == 0 {
+			if *genFakeServer {
+				fo.WriteString(fakeHandler(k))
+			}
 			continue
 		}
 		fo.WriteString(requester(k))
@@ -196,10 +285,94 @@ func main() {
 		fo.WriteString(xmlMarshaler(k))
 		// fo.WriteString(fmt.Sprintf("//go:generate xsdbay -check=%d -latest -v -e=%s\r\n", hash(val.String()), k))
 		fo.WriteString(validator(k, val.String()))
+		fo.WriteString(defaulter(k, Defaults[k].String()))
+		fo.WriteString(constructor(k))
+		fo.WriteString(paginator(k))
+		if s := streamers(k); s != "" {
+			fo.WriteString(s)
+			hasStreamers = true
+		}
+		if *genFakeServer {
+			fo.WriteString(fakeHandler(k))
+		}
+	}
+
+	if profile.Credentials && withCalls {
+		for _, pkg := range []string{"fmt", "net/url", "sync", "time"} {
+			Imports[pkg] = true
+		}
+		fo.WriteString(templateCredentials)
+	}
+
+	Imports["fmt"] = true
+	fo.WriteString(templateValidation)
+	fo.WriteString(validationTypes())
+
+	if *validateResponses {
+		fo.WriteString(templateResponseValidation)
+	}
+
+	if hasStreamers {
+		fo.WriteString(templateStream)
+	}
+
+	if *genRecorder {
+		for _, pkg := range []string{"fmt", "hash/fnv", "io/ioutil", "os", "path/filepath", "regexp"} {
+			Imports[pkg] = true
+		}
+		fo.WriteString(templateRecorder)
+	}
+
+	if *genFakeServer {
+		for _, pkg := range []string{"fmt", "net/http/httptest", "sync"} {
+			Imports[pkg] = true
+		}
+		fo.WriteString(templateFakeServer)
+	}
+
+	if *genSOAP {
+		for _, pkg := range []string{"fmt", "io/ioutil"} {
+			Imports[pkg] = true
+		}
+		fo.WriteString(soap)
+	}
+
+	if *genNotify {
+		for _, pkg := range []string{"crypto/md5", "crypto/subtle", "encoding/base64", "fmt", "io/ioutil", "sync", "time"} {
+			Imports[pkg] = true
+		}
+		fo.WriteString(notificationHandlers())
 	}
 
-	fw.Write(formatCode(fo.Bytes()))
+	if *genBulk {
+		for _, pkg := range []string{"bufio", "compress/gzip", "fmt"} {
+			Imports[pkg] = true
+		}
+		fo.WriteString(bulkExchange())
+	}
+
+	var header *bytes.Buffer
+	if withCalls {
+		header = bytes.NewBufferString(fmt.Sprintf(templateEbaySVC, importBlock(), profile.runtime(*apiVersion), profile.Namespace, profile.CallNameHeader))
+	} else {
+		// Schemas without calls get their types and validation only, none
+		// of the API runtime.
+		Imports = map[string]bool{"database/sql": true, "encoding/json": true, "encoding/xml": true, "errors": true, "fmt": true, "strconv": true, "strings": true}
+		header = bytes.NewBufferString(fmt.Sprintf(templateTypes, importBlock()))
+	}
+	header.Write(fo.Bytes())
+
+	fw.Write(formatCode(header.Bytes()))
 	fw.Close()
+	writeRuleSet()
+	writeJSONSchemas()
+	writeOpenAPI()
+	writeTypeScript()
+	writeProto()
+	writeProtoConverters()
+	writeSQL()
+	writeAuth()
+	writeDocs()
 	log.Printf("Completed in %s.", time.Since(start))
 }
 
@@ -216,13 +389,44 @@ func main() {
 // }
 
 func loadAllCalls() {
-	for _, e := range getSchema().Element {
-		if strings.HasSuffix(e.Name, "Request") {
-			exportedElements = append(exportedElements, strings.TrimSuffix(e.Name, "Request"))
+	if ns := getSchema().TargetNamespace; ns != profile.Namespace {
+		log.Fatalf("schema namespace %s is not the one of -api %s (%s), select the API of the schema with -api", ns, *apiName, profile.Namespace)
+	}
+	exportedElements = appInfoExt.Calls(getSchema())
+}
+
+// callFlagsUnset stops schemas without calls from being generated with the
+// flags adding API runtime code.
+func callFlagsUnset() {
+	for name, set := range map[string]bool{
+		"-recorder":          *genRecorder,
+		"-fake-server":       *genFakeServer,
+		"-soap":              *genSOAP,
+		"-notifications":     *genNotify,
+		"-bulk":              *genBulk,
+		"-validate-response": *validateResponses,
+		"-auth-go":           *authGoFile != "",
+	} {
+		if set {
+			log.Fatalf("%s needs a schema with calls, -appinfo %s finds none", name, *appInfoName)
 		}
 	}
 }
 
+func importBlock() string {
+	var pkgs []string
+	for pkg := range Imports {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	b := NewBuffer()
+	for _, pkg := range pkgs {
+		b.Sprintf("\t%q\r\n", pkg)
+	}
+	return b.String()
+}
+
 func formatCode(b []byte) []byte {
 	source, err := format.Source(b)
 	if err != nil {
@@ -247,7 +451,18 @@ func formatCode(b []byte) []byte {
 	return source
 }
 
+// UpperFirstLetter returns x as an exported Go identifier: the first letter in
+// upper case and characters not allowed in identifiers replaced by '_'.
 func UpperFirstLetter(x string) string {
+	if x == "" {
+		return x
+	}
+	x = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, x)
 	return strings.ToUpper(x[0:1]) + x[1:]
 }
 
@@ -276,6 +491,7 @@ func FromRequest(name string) {
 			x, _ := FindComplex(e.Type.String())
 			x.Generate()
 			x.Validator(name, "")
+			x.Defaulter(name, "")
 			x.Setter("")
 			return
 		}
@@ -288,6 +504,9 @@ func FromResponse(name string) {
 		if e.Name == name+"Response" {
 			x, _ := FindComplex(e.Type.String())
 			x.Generate()
+			if *validateResponses {
+				x.Validator(name+"Response", "")
+			}
 			x.Setter("")
 			return
 		}
@@ -295,6 +514,48 @@ func FromResponse(name string) {
 	log.Fatal("could not find element: " + name + "Response")
 }
 
+// rootElements maps the types of the top-level elements generated by
+// FromElements to the XML name of the element.
+var rootElements = map[string]string{}
+
+// FromElements generates a type for each top-level element of a schema
+// without calls, or for the elements named.
+func FromElements(names []string) {
+	var roots []Xyer
+	for _, e := range getSchema().Element {
+		if len(names) > 0 && !contains(names, e.Name) {
+			continue
+		}
+		x := e.GetRelated()
+		if x == nil {
+			log.Printf("Element %s is of type %s, no type generated", e.Name, e.Type)
+			continue
+		}
+		log.Printf("Element: %s", e.Name)
+		if _, ok := rootElements[e.Type.String()]; !ok {
+			rootElements[e.Type.String()] = strings.TrimSpace(getSchema().TargetNamespace + " " + e.Name)
+		}
+		roots = append(roots, x)
+	}
+	for _, name := range names {
+		if !elementExists(name) {
+			log.Fatal("could not find element: " + name)
+		}
+	}
+	for _, x := range roots {
+		x.Generate()
+	}
+}
+
+func elementExists(name string) bool {
+	for _, e := range getSchema().Element {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func Find(name string) Xyer {
 	if b, ok := FindComplex(name); ok {
 		return b
@@ -4,120 +4,94 @@ package main
 var templateEbaySVC = `package ebaysvc
 
 import (
-	"encoding/json"
-	"bytes"
-	"database/sql"
-	"encoding/xml"
-	"errors"
-	"net/http"
-	"strconv"
-	"io"
-	"strings"
+%[1]s)
+
+const (
+	// xmlNamespace is the namespace of the API's requests and responses.
+	xmlNamespace = %[3]q
+
+	// callNameHeader is the HTTP header naming the call of a request.
+	callNameHeader = %[4]q
 )
 
 var (
-	APIGateway string
-
-	// X-EBAY-API-COMPATIBILITY-LEVEL
-	// Required: Always.
-	// The eBay release version that your application supports. See the eBay Schema Versioning Strategy for information about how the version affects the way eBay processes your request.
-	APICompatibilityLevel string = "%[1]s"
-
-	// X-EBAY-API-SITEID
-	// Required: Always
-	// eBay site to which you want to send the request. See SiteCodeType for a list of valid site ID values. This is usually the eBay site an item is listed on or that a user is
-	// registered on, depending on the purpose of the call. See Specifying the Target Site to understand how the site ID may affect validation of the call and how it may affect
-	// the data that is returned. For calls like AddItem, the site that you pass in the body of the request must be consistent with this header. Note: In AddItem, you specify
-	// the 2-letter site code. In this header, you specify the numeric site ID.
-	// APISiteID string
-
-	// X-EBAY-API-DEV-NAME
-	// Required: Conditionally
-	// Your Developer ID (DevID), as registered with the eBay Developers Program. The developer ID is unique to each licensed developer (or company).
-	// This value is only required for calls that set up and retrieve a user's authentication token (these calls are: GetSessionID, FetchToken, GetTokenStatus, and RevokeToken).
-	// In all other calls, this value is ignored.. If you lose your keys you can retrieve them using the View Keys link on your My Account page. Here is the direct link to the Keys
-	// page (requires signin): http://developer.ebay.com/DevZone/account/keys.asp
-	APIDevName string
-
-	// X-EBAY-API-APP-NAME
-	// Required: Conditionally
-	// Your application ID (AppID), as registered with the eBay Developers Program. This value is only required for calls that set up and retrieve a user's authentication
-	// token (e.g., FetchToken). In all other calls, this value is ignored. Do not specify this value in AddItem and other calls that list items. The application ID is unique
-	// to each application created by the developer. The application ID and certificate ID are issued in pairs. Multiple application/certificate ID pairs can be issued for a
-	// single developer ID.
-	APIAppName string
-
-	// X-EBAY-API-CERT-NAME
-	// Required: Conditionally
-	// Your certificate ID (CertID), as registered with the eBay Developers Program. This value is only required for calls that set up and retrieve a user's authentication token
-	// (e.g., FetchToken). In all other calls, this value is ignored. Do not specify this value in AddItem and other calls that list items. The certificate ID is unique to each
-	// application created by the developer.
-	APICertName string
-
-	ErrAPIAppNameNotSet  error = errors.New("APIAppName is not set")
-	ErrAPIDevNameNotSet  error = errors.New("APIDevName is not set")
-	ErrAPICertNameNotSet error = errors.New("APICertName is not set")
-	ErrAPISiteIDNotSet   error = errors.New("APISiteID is not set")
-	ErrAPIGatewayNotSet  error = errors.New("APIGateway is not set")
+	// HTTPClient is used for every call made by the generated requesters.
+	// Replace it (or its Transport) to route calls through a proxy or a Recorder.
+	HTTPClient *http.Client = &http.Client{}
+
+	ErrAPIGatewayNotSet error = errors.New("APIGateway is not set")
 
 	RequestValidation bool
 )
-
+%[2]s
 type xbayRequester struct {
+	ctx      context.Context
 	callName string
 	siteID   string
 	body     *bytes.Buffer
 	response interface{}
+
+	// header holds the headers the credentials add.
+	header http.Header
+
+	// decode replaces decoding of the whole response into response.
+	decode func(io.Reader) error
 }
 
-func newRequester(callname, siteID string, response interface{}) *xbayRequester {
+func newRequester(ctx context.Context, callname, siteID string, response interface{}) *xbayRequester {
 	return &xbayRequester{
+		ctx:      ctx,
 		callName: callname,
 		siteID:   siteID,
 		body:     bytes.NewBufferString(xml.Header),
 		response: response,
+		header:   http.Header{},
 	}
 }
 
-func (x *xbayRequester) request() error {
-	if x.siteID == "" {
-		return ErrAPISiteIDNotSet
-	}
-	if APIGateway == "" {
-		return ErrAPIGatewayNotSet
+// send sends the request built by request() and decodes the response.
+func (x *xbayRequester) send(request *http.Request) error {
+	for name, values := range x.header {
+		request.Header[name] = values
 	}
-	client := &http.Client{}
-	request, err := http.NewRequest("POST", APIGateway, x.body)
+	response, err := HTTPClient.Do(request)
 	if err != nil {
 		return err
 	}
-
-	switch x.callName {
-	case "GetSessionID", "FetchToken", "GetTokenStatus", "RevokeToken":
-		if APIDevName == "" {
-			return ErrAPIDevNameNotSet
-		}
-		if APIAppName == "" {
-			return ErrAPIAppNameNotSet
-		}
-		if APICertName == "" {
-			return ErrAPICertNameNotSet
-		}
-		request.Header.Add("X-EBAY-API-DEV-NAME", APIDevName)
-		request.Header.Add("X-EBAY-API-APP-NAME", APIAppName)
-		request.Header.Add("X-EBAY-API-CERT-NAME", APICertName)
-	}
-	request.Header.Add("X-EBAY-API-COMPATIBILITY-LEVEL", APICompatibilityLevel)
-	request.Header.Add("X-EBAY-API-SITEID", x.siteID)
-	request.Header.Add("X-EBAY-API-CALL-NAME", x.callName)
-
-	response, err := client.Do(request)
-	if err != nil {
-		return err
+	defer response.Body.Close()
+	if x.decode != nil {
+		return x.decode(response.Body)
 	}
 	return xml.NewDecoder(response.Body).Decode(x.response)
 }
 
+type XmlnsAttr byte
+
+func (m XmlnsAttr) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{name, xmlNamespace}, nil
+}
+
+func (m *XmlnsAttr) UnmarshalXMLAttr(attr xml.Attr) error {
+	return nil
+}
+`
+
+// templateTypes is the header of the file generated for a schema without
+// calls.
+var templateTypes = `package ebaysvc
+
+import (
+%[1]s)
+`
+
+var templateResponseValidation = `
+// ResponseValidation checks every decoded response with its Validate() method.
+// Fields documented as always returned must be set and enumerations must hold
+// a known value.
+var ResponseValidation bool
+`
+
+var templateNulls = `
 func contains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
@@ -127,14 +101,6 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-type XmlnsAttr byte
-
-func (m XmlnsAttr) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
-	return xml.Attr{name, "urn:ebay:apis:eBLBaseComponents"}, nil
-}
-`
-
-var templateNulls = `
 type NullInt64 struct {
 	sql.NullInt64
 }
@@ -19,6 +19,10 @@ func (c attribute) Generate() {
 func (e attribute) Setter(callName string) {}
 
 func (e attribute) DeepValidator(callName, path string) bool {
+	if strings.HasSuffix(callName, "Response") || e.TypeDetails().Enum != "" {
+		_, yes := e.NeedsValidation(callName)
+		return yes
+	}
 	return false
 	if e.Annotation.AppInfo.MaxDepth != 0 {
 		return false
@@ -34,10 +38,12 @@ func (e attribute) DeepValidator(callName, path string) bool {
 }
 
 func (e attribute) Validator(callName, path string) {
-	if rules, ok := e.NeedsValidation(callName); ok {
-		details := e.TypeDetails()
+	details := e.TypeDetails()
+	fieldPath := NormalisePath(path + "." + UpperFirstLetter(details.Field))
+	if rules, ok := e.NeedsValidation(callName); ok || hasRules(callName, fieldPath) {
+		rules = fieldRules(callName, fieldPath).Apply(rules)
 		for _, rule := range rules {
-			Validator[callName].Sprintf("%s\r\n", details.ValidationString(rule, path))
+			emitRule(callName, details, rule, path)
 			if x := e.GetRelated(); x != nil {
 				x.Validator(callName, path+"."+details.Field)
 			}
@@ -45,40 +51,69 @@ func (e attribute) Validator(callName, path string) {
 	}
 }
 
+func (e attribute) DeepDefaulter(callName string) bool {
+	return e.Fixed != "" || e.Default != ""
+}
+
+func (e attribute) Defaulter(callName, path string) {
+	value := e.Fixed
+	if value == "" {
+		value = e.Default
+	}
+	if value == "" {
+		return
+	}
+	details := e.TypeDetails()
+	if set, err := details.Assign(path, value); err != nil {
+		log.Printf("Could not apply default value of %s.%s, skipping. Error: `%s`\r\nValue: `%v`", path, e.GetName(), err, value)
+	} else {
+		Defaults[callName].Sprintf("if %s {\r\n%s\r\n}\r\n", details.IsSet(path+"."+UpperFirstLetter(details.Field)), set)
+	}
+}
+
 func (e attribute) TypeDetails() *TypeDetails {
 	t := &TypeDetails{}
 	t.Field = e.GetName()
 	t.Type = e.GetType()
 
-	if e.Annotation != nil {
-		if listBasedOn, ok := e.Annotation.AppInfo.ListBasedOn(); ok {
-			if !strings.Contains(listBasedOn, ",") {
-				if x, ok := FindSimple(listBasedOn); ok {
-					t.AliasFor = x.GetType()
-				} else {
-					log.Fatalf("could not find simple type -`%s`- ", listBasedOn)
-				}
-			}
-		} else {
-			if x, ok := FindSimple(e.GetType().String()); ok {
-				t.SimpleType = true
+	if listBasedOn, ok := appInfoExt.ListBasedOn(e.Annotation); ok {
+		if !strings.Contains(listBasedOn, ",") {
+			if x, ok := FindSimple(listBasedOn); ok {
 				t.AliasFor = x.GetType()
+			} else {
+				log.Fatalf("could not find simple type -`%s`- ", listBasedOn)
+			}
+		}
+	} else {
+		if x, ok := FindSimple(e.GetType().String()); ok {
+			t.SimpleType = true
+			t.AliasFor = x.GetType()
+			if x.IsEnumeration() {
+				t.Enum = x.GetName()
 			}
 		}
 	}
 	if t.AliasFor == "" {
 		t.AliasFor = e.GetType()
 	}
+	// Only optional attributes use Null* types, see GoLine.
+	if e.Use != "optional" && e.GetType().IsXS() {
+		t.SimpleType = true
+	}
 	t.IsPointer = !e.GetType().Nullable() && !t.IsSlice
 	return t
 }
 
-func (c attribute) GoLine() string {
-	goType := c.GetType().GoType(false)
+// TransformType returns the Go type of the attribute's struct field.
+func (c attribute) TransformType() string {
 	if c.Use == "optional" {
-		goType = c.GetType().GoType()
+		return c.GetType().GoType()
 	}
-	return fmt.Sprintf("%[1]s %[2]s `xml:\"%[3]s,attr,omitempty\" json:\"%[4]s,omitempty\"` //attribute", UpperFirstLetter(c.GetName()), goType, c.GetName(), ToSnake(c.GetName()))
+	return c.GetType().GoType(false)
+}
+
+func (c attribute) GoLine() string {
+	return fmt.Sprintf("%[1]s %[2]s `xml:\"%[3]s,attr,omitempty\" json:\"%[4]s,omitempty\"` //attribute", UpperFirstLetter(c.GetName()), c.TransformType(), c.GetName(), ToSnake(c.GetName()))
 }
 
 func (c attribute) GetName() string {
@@ -14,8 +14,10 @@ func (c complexType) Generate() {
 	Types[c.GetName()] = NewBuffer()
 	Types[c.GetName()].Sprintf("type %s struct {\r\n", c.GetType())
 	if strings.HasSuffix(c.GetName(), "RequestType") && !c.Abstract && contains(exportedElements, strings.TrimSuffix(c.GetName(), "RequestType")) {
-		Types[c.GetName()].Sprintf("\tXMLName	xml.Name `xml:\"" + strings.TrimSuffix(c.Name, "Type") + "\" json:\"-\"`\r\n")
+		Types[c.GetName()].Sprintf("\tXMLName	xml.Name `xml:\"" + operationName(strings.TrimSuffix(c.Name, "RequestType")) + "Request\" json:\"-\"`\r\n")
 		Types[c.GetName()].Sprintf("\tXmlnsAttr `xml:\"xmlns,attr\" json:\"-\"`\r\n\r\n")
+	} else if name, ok := rootElements[c.GetName()]; ok && !c.Abstract {
+		Types[c.GetName()].Sprintf("\tXMLName	xml.Name `xml:\"%s\" json:\"-\"`\r\n\r\n", name)
 	}
 
 	for _, e := range c.GetElements() {
@@ -39,7 +41,38 @@ func (e complexType) Setter(typeName string) {
 	// }
 }
 
+// validating and deepValidating hold the complex types currently being walked,
+// so recursive types (ItemType -> ... -> ItemType) are only walked once per
+// branch. deepValidated caches DeepValidator results; results depending on a
+// cut branch (deepCuts changed) are not cached.
+var (
+	validating     = map[string]bool{}
+	deepValidating = map[string]bool{}
+	deepValidated  = map[string]bool{}
+	deepCuts       int
+)
+
 func (e complexType) DeepValidator(callName, path string) bool {
+	key := callName + ":" + e.Name
+	if v, ok := deepValidated[key]; ok {
+		return v
+	}
+	if deepValidating[e.Name] {
+		deepCuts++
+		return false
+	}
+	deepValidating[e.Name] = true
+	defer delete(deepValidating, e.Name)
+
+	cuts := deepCuts
+	v := e.deepValidator(callName, path)
+	if cuts == deepCuts {
+		deepValidated[key] = v
+	}
+	return v
+}
+
+func (e complexType) deepValidator(callName, path string) bool {
 	for _, x := range e.GetElements() {
 		pathX := path + "." + e.Name
 		if x.GetType().String() == e.Name {
@@ -59,10 +92,96 @@ func (e complexType) Validator(callName, path string) {
 	if path == "" {
 		path = "x"
 	}
+	if validating[e.Name] {
+		return
+	}
+	validating[e.Name] = true
+	defer delete(validating, e.Name)
 
 	for _, f := range e.GetElements() {
 		f.Validator(callName, path)
 	}
+	e.conditionalValidator(callName, path)
+}
+
+// conditionalValidator checks the fields only required when another field
+// holds a given value, see element.Conditions.
+func (e complexType) conditionalValidator(callName, path string) {
+	if strings.HasSuffix(callName, "Response") {
+		return
+	}
+	for _, f := range e.GetElements() {
+		x, ok := f.(element)
+		if !ok {
+			continue
+		}
+		for _, c := range x.Conditions(callName, NormalisePath(path)) {
+			expr, err := conditionExpr(callName, path, c)
+			if err != nil {
+				log.Printf("Could not check condition `%s` of %s.%s, skipping validation line. Error: `%s`", c, path, x.GetName(), err)
+				continue
+			}
+			rule := ValidationRule{Type: ValTypRequiredIf, Value: requiredIf{Condition: c, Expr: expr}}
+			emitRule(callName, x.TypeDetails(), rule, path)
+		}
+	}
+}
+
+// defaulting and deepDefaulting guard recursive types the same way
+// validating and deepValidating do, deepDefaulted caches DeepDefaulter
+// results like deepValidated.
+var (
+	defaulting     = map[string]bool{}
+	deepDefaulting = map[string]bool{}
+	deepDefaulted  = map[string]bool{}
+	defaultCuts    int
+)
+
+func (e complexType) DeepDefaulter(callName string) bool {
+	key := callName + ":" + e.Name
+	if v, ok := deepDefaulted[key]; ok {
+		return v
+	}
+	if deepDefaulting[e.Name] {
+		defaultCuts++
+		return false
+	}
+	deepDefaulting[e.Name] = true
+	defer delete(deepDefaulting, e.Name)
+
+	cuts := defaultCuts
+	v := e.deepDefaulter(callName)
+	if cuts == defaultCuts {
+		deepDefaulted[key] = v
+	}
+	return v
+}
+
+func (e complexType) deepDefaulter(callName string) bool {
+	for _, x := range e.GetElements() {
+		if x.DeepDefaulter(callName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e complexType) Defaulter(callName, path string) {
+	if _, ok := Defaults[callName]; !ok {
+		Defaults[callName] = NewBuffer()
+	}
+	if path == "" {
+		path = "x"
+	}
+	if defaulting[e.Name] {
+		return
+	}
+	defaulting[e.Name] = true
+	defer delete(defaulting, e.Name)
+
+	for _, f := range e.GetElements() {
+		f.Defaulter(callName, path)
+	}
 }
 
 func (c complexType) GoLine() string {
@@ -95,56 +214,51 @@ func (c complexType) GetElements() (r []Xyer) {
 	if strings.HasSuffix(c.Name, "ResponseType") && !c.Abstract {
 		callID = strings.TrimSuffix(c.Name, "ResponseType")
 	}
-	if c.SimpleContent != nil {
+	return c.fields(callID, request)
+}
+
+// fields returns the elements and attributes of c the request (or response)
+// of callID has, starting with those of the type c extends.
+func (c complexType) fields(callID string, request bool) (r []Xyer) {
+	included := func(a *annotation) bool {
+		return !appInfoExt.Skip(a) && appInfoExt.IncludedIn(a, callID, request)
+	}
+	if c.SimpleContent != nil && c.SimpleContent.Extension != nil {
 		for _, a := range c.SimpleContent.Extension.Attribute {
-			if a.Annotation.Skip() || !a.Annotation.IncludedIn(callID, request) {
-				continue
+			if included(a.Annotation) {
+				r = append(r, a)
 			}
-			r = append(r, a)
 		}
 		r = append(r, c.SimpleContent.Extension)
 	}
-	if c.ComplexContent != nil {
-		if c.ComplexContent.Restriction != nil {
-			log.Fatal("complexType.ComplexContent.Restriction: not null at ", c.Name)
+	if c.ComplexContent != nil && c.ComplexContent.Extension != nil {
+		x := c.ComplexContent.Extension
+		if base, ok := FindComplex(x.Base.String()); !ok {
+			log.Fatalf("could not find complex type: %s", x.Base)
+		} else {
+			r = append(r, base.fields(callID, request)...)
 		}
-
-		if c.ComplexContent.Extension != nil {
-			if base, ok := FindComplex(c.ComplexContent.Extension.Base.String()); !ok {
-				log.Fatalf("could not find complex type: %s", c.GetType())
-			} else {
-				for _, e := range base.Sequence.Element {
-					if e.Annotation.Skip() || !e.Annotation.IncludedIn(callID, request) {
-						continue
-					}
-					r = append(r, e)
-				}
+		for _, e := range x.Sequence.Element {
+			if included(e.Annotation) {
+				r = append(r, e)
 			}
-
-			if len(c.ComplexContent.Extension.Attribute) > 0 {
-				log.Fatal("BASE.Attribute: not null at ", c.Name)
-			}
-
-			for _, s := range c.ComplexContent.Extension.Sequence.Element {
-				if s.Annotation.Skip() || !s.Annotation.IncludedIn(callID, request) {
-					continue
-				}
-				r = append(r, s)
+		}
+		for _, a := range x.Attribute {
+			if included(a.Annotation) {
+				r = append(r, a)
 			}
 		}
 	}
 	for _, a := range c.Attribute {
-		if a.Annotation.Skip() || !a.Annotation.IncludedIn(callID, request) {
-			continue
+		if included(a.Annotation) {
+			r = append(r, a)
 		}
-		r = append(r, a)
 	}
 	if c.Sequence != nil {
 		for _, e := range c.Sequence.Element {
-			if e.Annotation.Skip() || !e.Annotation.IncludedIn(callID, request) {
-				continue
+			if included(e.Annotation) {
+				r = append(r, e)
 			}
-			r = append(r, e)
 		}
 	}
 	return
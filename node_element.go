@@ -42,7 +42,7 @@ func (e element) Setter(typeName string) {
 				Funcs[funcIdx] = NewBuffer()
 				funcCount := 0
 				for _, e := range splx.Restriction.Enumeration {
-					if e.Annotation.Skip() || e.Value == "CustomCode" {
+					if appInfoExt.Skip(&e.Annotation) || e.Value == "CustomCode" {
 						continue
 					}
 					Funcs[funcIdx].Sprintf(`func (x %[1]s) %[2]s() bool {
@@ -72,59 +72,106 @@ func (e element) DeepValidator(callName, path string) bool {
 }
 
 func (e element) Validator(callName, path string) {
+	newPath := fmt.Sprintf("%s.%s", path, UpperFirstLetter(e.GetName()))
 	rules2, ok := e.NeedsValidation(callName)
-	if !ok {
+	if !ok && !hasRules(callName, NormalisePath(newPath)) {
 		return
 	}
-	rules := rules2.Except(ValTypRequired, ValTypMaxOccurs)
 	related := e.GetRelated()
 	key := ""
-	newPath := fmt.Sprintf("%s.%s", path, UpperFirstLetter(e.GetName()))
-	hasDeepValidationRequirement := false
-	loopBracket, pointerBracket := false, false
-	if related != nil {
-		if e.Annotation.AppInfo.MaxDepth == 0 {
-			hasDeepValidationRequirement = related.DeepValidator(callName, newPath)
-		}
-	}
+	rules2 = fieldRules(callName, NormalisePath(newPath)).Apply(rules2)
+	rules := rules2.Except(ValTypRequired, ValTypRequiredIf, ValTypReturned, ValTypMinOccurs, ValTypMaxOccurs)
+	v := Validator[callName]
 
 	if rule, yes := rules2.Includes(ValTypMaxOccurs); yes {
-		Validator[callName].Sprintf("%s", e.TypeDetails().Key(key).ValidationString(*rule, path))
+		emitRule(callName, e.TypeDetails().Key(key), *rule, path)
+	}
+	if rule, yes := rules2.Includes(ValTypMinOccurs); yes {
+		emitRule(callName, e.TypeDetails().Key(key), *rule, path)
 	}
 	if rule, yes := rules2.Includes(ValTypRequired); yes {
-		Validator[callName].Sprintf("%s", e.TypeDetails().Key(key).ValidationString(*rule, path))
+		emitRule(callName, e.TypeDetails().Key(key), *rule, path)
 	}
-
-	if e.TypeDetails().IsPointer && hasDeepValidationRequirement {
-		pointerBracket = true
-		Validator[callName].Sprintf("if %s != nil {\r\n", newPath)
+	if rule, yes := rules2.Includes(ValTypReturned); yes {
+		emitRule(callName, e.TypeDetails().Key(key), *rule, path)
 	}
 
+	// Validate() collects every error, so nested checks are always guarded.
+	// The guard is dropped again if nothing was written inside it.
+	bracket := v.Len()
+	if e.TypeDetails().IsPointer {
+		v.Sprintf("if %s != nil {\r\n", newPath)
+	}
 	if e.TypeDetails().IsSlice {
 		key = fmt.Sprintf("i%d", hash(path))
 		newPath = path + "." + UpperFirstLetter(e.GetName()) + "[" + key + "]"
-
-		if rules.Len() > 0 || hasDeepValidationRequirement {
-			loopBracket = true
-			Validator[callName].Sprintf("for %s := range %s {\r\n", key, fmt.Sprintf("%s.%s", path, UpperFirstLetter(e.GetName())))
-		} else {
-			//Validator[callName].Sprintf("// No validation for %s %+v | %v\r\n", newPath, rules, rules2)
-		}
+		v.Sprintf("for %s := range %s {\r\n", key, fmt.Sprintf("%s.%s", path, UpperFirstLetter(e.GetName())))
 	}
+	body := v.Len()
 
 	for _, r := range rules {
-		Validator[callName].Sprintf("%s", e.TypeDetails().Key(key).ValidationString(r, path))
+		emitRule(callName, e.TypeDetails().Key(key), r, path)
 	}
 
 	if related != nil {
 		related.Validator(callName, newPath)
 	}
 
-	if loopBracket {
-		Validator[callName].Sprintf("}\r\n")
+	if v.Len() == body {
+		v.Truncate(bracket)
+		return
 	}
-	if pointerBracket {
-		Validator[callName].Sprintf("}\r\n")
+	if e.TypeDetails().IsSlice {
+		v.Sprintf("}\r\n")
+	}
+	if e.TypeDetails().IsPointer {
+		v.Sprintf("}\r\n")
+	}
+}
+
+func (e element) DeepDefaulter(callName string) bool {
+	if _, ok := appInfoExt.DefaultFor(e.Annotation, callName); ok {
+		return true
+	}
+	if related := e.GetRelated(); related != nil && e.GetType().IsComplexType() {
+		return related.DeepDefaulter(callName)
+	}
+	return false
+}
+
+// Defaulter sets unset fields to their documented default. Nested types are
+// only filled in when the caller has already created them.
+func (e element) Defaulter(callName, path string) {
+	d := Defaults[callName]
+	details := e.TypeDetails()
+	newPath := fmt.Sprintf("%s.%s", path, UpperFirstLetter(e.GetName()))
+
+	if value, ok := appInfoExt.DefaultFor(e.Annotation, callName); ok {
+		if set, err := details.Assign(path, value); err != nil {
+			log.Printf("Could not apply default value of %s, skipping. Error: `%s`\r\nValue: `%v`", newPath, err, value)
+		} else {
+			d.Sprintf("if %s {\r\n%s\r\n}\r\n", details.IsSet(newPath), set)
+		}
+	}
+
+	related := e.GetRelated()
+	if related == nil || !e.GetType().IsComplexType() || !related.DeepDefaulter(callName) {
+		return
+	}
+	if details.IsPointer {
+		d.Sprintf("if %s != nil {\r\n", newPath)
+	}
+	if details.IsSlice {
+		key := fmt.Sprintf("i%d", hash(path))
+		d.Sprintf("for %s := range %s {\r\n", key, newPath)
+		newPath = newPath + "[" + key + "]"
+	}
+	related.Defaulter(callName, newPath)
+	if details.IsSlice {
+		d.Sprintf("}\r\n")
+	}
+	if details.IsPointer {
+		d.Sprintf("}\r\n")
 	}
 }
 
@@ -133,20 +180,24 @@ func (e element) TypeDetails() *TypeDetails {
 	t.Field = e.GetName()
 	t.Type = e.GetType()
 	_, t.IsSlice = e.SliceLen()
-	if e.Annotation != nil {
-		if listBasedOn, ok := e.Annotation.AppInfo.ListBasedOn(); ok {
-			if !strings.Contains(listBasedOn, ",") {
-				if x, ok := FindSimple(listBasedOn); ok {
-					t.SimpleType = true
-					t.AliasFor = x.GetType()
-				} else {
-					log.Fatalf("could not find simple type -`%s`- ", listBasedOn)
-				}
-			}
-		} else {
-			if x, ok := FindSimple(e.GetType().String()); ok {
+	if listBasedOn, ok := appInfoExt.ListBasedOn(e.Annotation); ok {
+		if !strings.Contains(listBasedOn, ",") {
+			if x, ok := FindSimple(listBasedOn); ok {
 				t.SimpleType = true
 				t.AliasFor = x.GetType()
+				if x.IsEnumeration() {
+					t.Enum = x.GetName()
+				}
+			} else {
+				log.Fatalf("could not find simple type -`%s`- ", listBasedOn)
+			}
+		}
+	} else {
+		if x, ok := FindSimple(e.GetType().String()); ok {
+			t.SimpleType = true
+			t.AliasFor = x.GetType()
+			if x.IsEnumeration() {
+				t.Enum = x.GetName()
 			}
 		}
 	}
@@ -160,22 +211,20 @@ func (e element) TypeDetails() *TypeDetails {
 func (e element) TransformType() string {
 	var let Type = e.Type
 
-	if e.Annotation != nil {
-		if listBasedOn, ok := e.Annotation.AppInfo.ListBasedOn(); ok {
-			if !strings.Contains(listBasedOn, ",") {
-				if Type(listBasedOn).Nullable() {
-					let = Type(listBasedOn)
-				}
-				if x, ok := FindSimple(listBasedOn); ok {
+	if listBasedOn, ok := appInfoExt.ListBasedOn(e.Annotation); ok {
+		if !strings.Contains(listBasedOn, ",") {
+			if Type(listBasedOn).Nullable() {
+				let = Type(listBasedOn)
+			}
+			if x, ok := FindSimple(listBasedOn); ok {
+				x.Generate()
+			}
+		} else {
+			listBasedOn = strings.Replace(listBasedOn, " ", "", -1)
+			for _, k := range strings.Split(listBasedOn, ",") {
+				if x, ok := FindSimple(k); ok {
 					x.Generate()
 				}
-			} else {
-				listBasedOn = strings.Replace(listBasedOn, " ", "", -1)
-				for _, k := range strings.Split(listBasedOn, ",") {
-					if x, ok := FindSimple(k); ok {
-						x.Generate()
-					}
-				}
 			}
 		}
 	}
@@ -16,14 +16,20 @@ func (c extensionSimpleContent) Generate() {
 func (e extensionSimpleContent) Setter(typeName string) {}
 
 func (e extensionSimpleContent) DeepValidator(callName, path string) bool {
-	if e.Annotation.RequiredFor(callName) {
+	if appInfoExt.RequiredFor(&e.Annotation, callName) {
 		return true
 	}
 	return false
 }
 
+func (e extensionSimpleContent) DeepDefaulter(callName string) bool {
+	return false
+}
+
+func (e extensionSimpleContent) Defaulter(callName, path string) {}
+
 func (e extensionSimpleContent) Validator(callName, path string) {
-	if e.Annotation.RequiredFor(callName) {
+	if appInfoExt.RequiredFor(&e.Annotation, callName) {
 		Validator[callName].Sprintf("//extensionSimpleContent.Validator %s %s\r\n", callName, path)
 	}
 }
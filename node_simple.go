@@ -57,18 +57,53 @@ func (c simpleType) Generate() {
 func (e simpleType) Setter(typeName string) {}
 
 func (e simpleType) DeepValidator(callName, path string) bool {
-	if e.Annotation.RequiredFor(callName) {
+	if appInfoExt.RequiredFor(&e.Annotation, callName) {
 		return true
 	}
 	return false
 }
 
+func (e simpleType) DeepDefaulter(callName string) bool {
+	return false
+}
+
+func (e simpleType) Defaulter(callName, path string) {}
+
 func (e simpleType) Validator(callName, path string) {
-	if e.Annotation.RequiredFor(callName) {
+	if appInfoExt.RequiredFor(&e.Annotation, callName) {
 		Validator[callName].Sprintf("//%s.%s // Simple: %s\r\n", path, e.GetName(), callName)
 	}
 }
 
+func (c simpleType) IsEnumeration() bool {
+	return c.Restriction != nil && len(c.Restriction.Enumeration) > 0
+}
+
+// Values returns the values of the generated *CodeTypeList.
+func (c simpleType) Values() (values []string) {
+	if !c.IsEnumeration() {
+		return
+	}
+	for i, e := range c.Restriction.Enumeration {
+		if i == 0 || e.Value != "CustomCode" {
+			values = append(values, e.Value)
+		}
+	}
+	return
+}
+
+func (c simpleType) HasValue(value string) bool {
+	if !c.IsEnumeration() {
+		return true
+	}
+	for _, e := range c.Restriction.Enumeration {
+		if e.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
 func (c simpleType) GoLine() string {
 	return fmt.Sprintf("//8%s %s //simple", UpperFirstLetter(c.GetName()), c.GetType())
 }
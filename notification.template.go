@@ -0,0 +1,180 @@
+package main
+
+// Platform Notifications handler. Emitted with -notifications.
+var templateNotifications = `
+// NotificationHandler is an http.Handler receiving eBay Platform
+// Notifications. It checks the signature of every notification, decodes its
+// body into the response type of the event and passes it to the callback
+// registered for the event:
+//
+//	h := NewNotificationHandler(devID, appID, certID)
+//	h.HandleItemSold(func(n *Notification, r *GetItemResponseType) error { ... })
+//	http.Handle("/ebay/notifications", h)
+//
+// Notifications without a callback are acknowledged and passed to Unhandled,
+// if set. A callback returning an error answers 500, so eBay sends the
+// notification again.
+type NotificationHandler struct {
+	DevID  string
+	AppID  string
+	CertID string
+
+	// MaxAge, when set, rejects notifications whose timestamp is further
+	// from the current time, e.g. replayed ones.
+	MaxAge time.Duration
+
+	// Unhandled is called with notifications of events without a callback.
+	Unhandled func(n *Notification) error
+
+	mu        sync.RWMutex
+	callbacks map[string]notificationCallback
+}
+
+// Notification describes a received Platform Notification.
+type Notification struct {
+	Event     string
+	Timestamp time.Time
+	Signature string
+
+	// Body holds the XML of the response the notification carries.
+	Body []byte
+}
+
+type notificationCallback struct {
+	// element is the body element of the event, e.g. GetItemResponse.
+	element string
+	handle  func(n *Notification) error
+}
+
+var (
+	ErrNotificationSignature = errors.New("notification signature does not match")
+	ErrNotificationExpired   = errors.New("notification timestamp is out of range")
+)
+
+func NewNotificationHandler(devID, appID, certID string) *NotificationHandler {
+	return &NotificationHandler{
+		DevID:     devID,
+		AppID:     appID,
+		CertID:    certID,
+		callbacks: make(map[string]notificationCallback),
+	}
+}
+
+func (h *NotificationHandler) handle(event, element string, fn func(n *Notification) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.callbacks == nil {
+		h.callbacks = make(map[string]notificationCallback)
+	}
+	h.callbacks[event] = notificationCallback{element: element, handle: fn}
+}
+
+// notificationEnvelope is the SOAP envelope of a notification.
+type notificationEnvelope struct {
+	Header struct {
+		Signature string ` + "`xml:\"RequesterCredentials>NotificationSignature\"`" + `
+	} ` + "`xml:\"Header\"`" + `
+	Body struct {
+		XML []byte ` + "`xml:\",innerxml\"`" + `
+	} ` + "`xml:\"Body\"`" + `
+}
+
+// notificationHead holds the fields every notification body has.
+type notificationHead struct {
+	XMLName   xml.Name
+	Timestamp string
+	Event     string ` + "`xml:\"NotificationEventName\"`" + `
+}
+
+// ParseNotification reads a notification from r and verifies its signature.
+// soapAction is the SOAPAction header, naming the event if the body does not.
+func (h *NotificationHandler) ParseNotification(r io.Reader, soapAction string) (*Notification, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	envelope := notificationEnvelope{}
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	head := notificationHead{}
+	if err := xml.Unmarshal(envelope.Body.XML, &head); err != nil {
+		return nil, err
+	}
+
+	n := &Notification{
+		Event:     head.Event,
+		Signature: strings.TrimSpace(envelope.Header.Signature),
+		Body:      bytes.TrimSpace(envelope.Body.XML),
+	}
+	if n.Event == "" {
+		soapAction = strings.Trim(soapAction, "\"")
+		n.Event = soapAction[strings.LastIndex(soapAction, "/")+1:]
+	}
+	if n.Timestamp, err = time.Parse(time.RFC3339, strings.TrimSpace(head.Timestamp)); err != nil {
+		return nil, fmt.Errorf("notification timestamp: %w", err)
+	}
+
+	if !h.validSignature(strings.TrimSpace(head.Timestamp), n.Signature) {
+		return nil, ErrNotificationSignature
+	}
+	if h.MaxAge > 0 {
+		if age := time.Since(n.Timestamp); age > h.MaxAge || age < -h.MaxAge {
+			return nil, ErrNotificationExpired
+		}
+	}
+	return n, nil
+}
+
+// validSignature checks the signature eBay computes as the Base64 encoded MD5
+// of the timestamp followed by the developer, application and certificate IDs.
+func (h *NotificationHandler) validSignature(timestamp, signature string) bool {
+	sum := md5.Sum([]byte(timestamp + h.DevID + h.AppID + h.CertID))
+	expected := base64.StdEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1
+}
+
+func (h *NotificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n, err := h.ParseNotification(r.Body, r.Header.Get("SOAPAction"))
+	switch err {
+	case nil:
+	case ErrNotificationSignature, ErrNotificationExpired:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Dispatch(n); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Dispatch passes n to the callback of its event.
+func (h *NotificationHandler) Dispatch(n *Notification) error {
+	h.mu.RLock()
+	callback, ok := h.callbacks[n.Event]
+	h.mu.RUnlock()
+	if !ok {
+		if h.Unhandled != nil {
+			return h.Unhandled(n)
+		}
+		return nil
+	}
+	return callback.handle(n)
+}
+
+// decodeNotification decodes the body of n into response, checking it is the
+// element the event is known to carry.
+func decodeNotification(n *Notification, element string, response interface{}) error {
+	head := notificationHead{}
+	if err := xml.Unmarshal(n.Body, &head); err != nil {
+		return err
+	}
+	if head.XMLName.Local != element {
+		return fmt.Errorf("notification %s carries %s, expected %s", n.Event, head.XMLName.Local, element)
+	}
+	return xml.Unmarshal(n.Body, response)
+}
+`
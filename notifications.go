@@ -0,0 +1,64 @@
+package main
+
+import "sort"
+
+// notificationEvents maps Platform Notification events to the call whose
+// response they carry.
+var notificationEvents = map[string]string{
+	"AskSellerQuestion":       "GetMemberMessages",
+	"AuctionCheckoutComplete": "GetItemTransactions",
+	"BestOffer":               "GetBestOffers",
+	"BestOfferDeclined":       "GetBestOffers",
+	"BestOfferPlaced":         "GetBestOffers",
+	"BidPlaced":               "GetItem",
+	"BidReceived":             "GetItem",
+	"EndOfAuction":            "GetItemTransactions",
+	"Feedback":                "GetFeedback",
+	"FeedbackLeft":            "GetFeedback",
+	"FeedbackReceived":        "GetFeedback",
+	"FixedPriceTransaction":   "GetItemTransactions",
+	"ItemClosed":              "GetItem",
+	"ItemExtended":            "GetItem",
+	"ItemListed":              "GetItem",
+	"ItemLost":                "GetItem",
+	"ItemRevised":             "GetItem",
+	"ItemSold":                "GetItem",
+	"ItemSuspended":           "GetItem",
+	"ItemUnsold":              "GetItem",
+	"ItemWon":                 "GetItem",
+	"MyMessagesM2MMessage":    "GetMyMessages",
+	"OutBid":                  "GetItem",
+	"UserIDChanged":           "GetUser",
+	"WatchedItemEndingSoon":   "GetItem",
+}
+
+// notificationHandlers returns a Handle<Event> method of NotificationHandler
+// for every event whose call is exported.
+func notificationHandlers() string {
+	var events []string
+	for event, call := range notificationEvents {
+		if contains(exportedElements, call) {
+			events = append(events, event)
+		}
+	}
+	sort.Strings(events)
+
+	b := NewBuffer()
+	for _, event := range events {
+		call := notificationEvents[event]
+		b.Sprintf(`// Handle%[1]s registers the callback of %[1]s notifications, which carry a
+			// %[2]s response.
+			func (h *NotificationHandler) Handle%[1]s(fn func(n *Notification, response *%[2]sResponseType) error) {
+				h.handle(%[1]q, %[3]q, func(n *Notification) error {
+					response := &%[2]sResponseType{}
+					if err := decodeNotification(n, %[3]q, response); err != nil {
+						return err
+					}
+					return fn(n, response)
+				})
+			}
+
+			`, event, call, operationName(call)+"Response")
+	}
+	return b.String() + templateNotifications
+}
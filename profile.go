@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// apiProfile describes an eBay XML API: the runtime sending its calls, the
+// namespace of its schema and how its operations are named.
+type apiProfile struct {
+	// Namespace is the target namespace of the API's schema.
+	Namespace string
+	// CallNameHeader is the HTTP header naming the call of a request.
+	CallNameHeader string
+	// Runtime is the template of the package variables and request(), see
+	// profile.template.go.
+	Runtime string
+	// Gateway is the default APIGateway.
+	Gateway string
+	// AppIDHeader is the HTTP header taking the application ID of SOA APIs.
+	AppIDHeader string
+	// Download is the latest schema of the API, for -latest.
+	Download string
+	// AppInfo is the default -appinfo dialect.
+	AppInfo string
+	// Title names the API in the -openapi document and the -docs reference.
+	Title string
+
+	// Credentials reports whether requesters take the user's Credentials,
+	// sent in RequesterCredentials or the X-EBAY-API-IAF-TOKEN header.
+	Credentials bool
+	// LowerCamel reports whether operations are named in lower camel case
+	// (findItemsByKeywords) with types without the Type suffix.
+	LowerCamel bool
+}
+
+// APIProfiles holds the APIs selectable with -api.
+var APIProfiles = map[string]apiProfile{
+	"trading": {
+		Namespace:      "urn:ebay:apis:eBLBaseComponents",
+		CallNameHeader: "X-EBAY-API-CALL-NAME",
+		Runtime:        templateTradingRuntime,
+		Download:       "http://developer.ebay.com/webservices/latest/ebaysvc.xsd",
+		AppInfo:        "ebay",
+		Title:          "eBay Trading API",
+		Credentials:    true,
+	},
+	"shopping": {
+		Namespace:      "urn:ebay:apis:eBLBaseComponents",
+		CallNameHeader: "X-EBAY-API-CALL-NAME",
+		Runtime:        templateShoppingRuntime,
+		Download:       "http://developer.ebay.com/webservices/latest/ShoppingService.xsd",
+		AppInfo:        "ebay",
+		Title:          "eBay Shopping API",
+	},
+	"finding": {
+		Namespace:      "http://www.ebay.com/marketplace/search/v1/services",
+		CallNameHeader: "X-EBAY-SOA-OPERATION-NAME",
+		Runtime:        templateSOARuntime,
+		Gateway:        "https://svcs.ebay.com/services/search/FindingService/v1",
+		AppIDHeader:    "X-EBAY-SOA-SECURITY-APPNAME",
+		Download:       "http://developer.ebay.com/webservices/finding/latest/FindingService.wsdl",
+		AppInfo:        "soa",
+		Title:          "eBay Finding API",
+		LowerCamel:     true,
+	},
+	"merchandising": {
+		Namespace:      "http://www.ebay.com/marketplace/services",
+		CallNameHeader: "X-EBAY-SOA-OPERATION-NAME",
+		Runtime:        templateSOARuntime,
+		Gateway:        "https://svcs.ebay.com/MerchandisingService",
+		AppIDHeader:    "EBAY-SOA-CONSUMER-ID",
+		Download:       "http://developer.ebay.com/webservices/latest/MerchandisingService.wsdl",
+		AppInfo:        "soa",
+		Title:          "eBay Merchandising API",
+		LowerCamel:     true,
+	},
+}
+
+var profile = APIProfiles["trading"]
+
+// loadProfile selects the -api profile and the defaults it gives -download
+// and -appinfo.
+func loadProfile() {
+	p, ok := APIProfiles[*apiName]
+	if !ok {
+		var names []string
+		for name := range APIProfiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		log.Fatalf("unknown -api %q, use one of: %s", *apiName, strings.Join(names, ", "))
+	}
+	profile = p
+
+	if *onlineXSD == "" {
+		*onlineXSD = profile.Download
+	}
+	if *appInfoName == "" {
+		*appInfoName = profile.AppInfo
+	}
+}
+
+// runtime returns the package variables and request() of the profile.
+func (p apiProfile) runtime(version string) string {
+	return fmt.Sprintf(p.Runtime, version, p.AppIDHeader, p.Gateway)
+}
+
+// requestParams returns the parameters of the generated requesters, the
+// arguments passing them on and the credentials argument of do(): the user's
+// Credentials are only taken by APIs with RequesterCredentials.
+func requestParams() (params, args, credentials string) {
+	if profile.Credentials {
+		return "credentials Credentials, siteID string", "credentials, siteID", ", credentials"
+	}
+	return "siteID string", "siteID", ""
+}
+
+// operationNames maps the calls of LowerCamel APIs to their operation.
+var operationNames = map[string]string{}
+
+// operationName returns the name callName is sent as.
+func operationName(callName string) string {
+	if op, ok := operationNames[callName]; ok {
+		return op
+	}
+	return callName
+}
+
+// renameOperations names the request and response of the operations of a
+// LowerCamel API like Trading API calls: findItemsByKeywordsRequest of type
+// FindItemsByKeywordsRequest becomes FindItemsByKeywordsRequest of type
+// FindItemsByKeywordsRequestType. The types are copied, as others may still
+// refer to them.
+func renameOperations(s *schema) {
+	if !profile.LowerCamel {
+		return
+	}
+	for i := range s.Element {
+		e := &s.Element[i]
+		suffix := "Request"
+		if strings.HasSuffix(e.Name, "Response") {
+			suffix = "Response"
+		} else if !strings.HasSuffix(e.Name, suffix) {
+			continue
+		}
+		c, ok := FindComplex(e.Type.String())
+		if !ok {
+			continue
+		}
+		op := strings.TrimSuffix(e.Name, suffix)
+		call := UpperFirstLetter(op)
+		c.Name = call + suffix + "Type"
+		if _, ok := FindComplex(c.Name); !ok {
+			s.ComplexType = append(s.ComplexType, *c)
+		}
+		e.Name, e.Type = call+suffix, Type(c.Name)
+		operationNames[call] = op
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+// Runtime of the -api profiles: the package variables and request() sending
+// the calls of the API. Formatted with the API version and, for the SOA APIs,
+// the header taking the application ID.
+
+var templateTradingRuntime = `
+var (
+	APIGateway string
+
+	// X-EBAY-API-COMPATIBILITY-LEVEL
+	// Required: Always.
+	// The eBay release version that your application supports. See the eBay Schema Versioning Strategy for information about how the version affects the way eBay processes your request.
+	APICompatibilityLevel string = "%[1]s"
+
+	// X-EBAY-API-SITEID
+	// Required: Always
+	// eBay site to which you want to send the request. See SiteCodeType for a list of valid site ID values. This is usually the eBay site an item is listed on or that a user is
+	// registered on, depending on the purpose of the call. See Specifying the Target Site to understand how the site ID may affect validation of the call and how it may affect
+	// the data that is returned. For calls like AddItem, the site that you pass in the body of the request must be consistent with this header. Note: In AddItem, you specify
+	// the 2-letter site code. In this header, you specify the numeric site ID.
+	// APISiteID string
+
+	// X-EBAY-API-DEV-NAME
+	// Required: Conditionally
+	// Your Developer ID (DevID), as registered with the eBay Developers Program. The developer ID is unique to each licensed developer (or company).
+	// This value is only required for calls that set up and retrieve a user's authentication token (these calls are: GetSessionID, FetchToken, GetTokenStatus, and RevokeToken).
+	// In all other calls, this value is ignored.. If you lose your keys you can retrieve them using the View Keys link on your My Account page. Here is the direct link to the Keys
+	// page (requires signin): http://developer.ebay.com/DevZone/account/keys.asp
+	APIDevName string
+
+	// X-EBAY-API-APP-NAME
+	// Required: Conditionally
+	// Your application ID (AppID), as registered with the eBay Developers Program. This value is only required for calls that set up and retrieve a user's authentication
+	// token (e.g., FetchToken). In all other calls, this value is ignored. Do not specify this value in AddItem and other calls that list items. The application ID is unique
+	// to each application created by the developer. The application ID and certificate ID are issued in pairs. Multiple application/certificate ID pairs can be issued for a
+	// single developer ID.
+	APIAppName string
+
+	// X-EBAY-API-CERT-NAME
+	// Required: Conditionally
+	// Your certificate ID (CertID), as registered with the eBay Developers Program. This value is only required for calls that set up and retrieve a user's authentication token
+	// (e.g., FetchToken). In all other calls, this value is ignored. Do not specify this value in AddItem and other calls that list items. The certificate ID is unique to each
+	// application created by the developer.
+	APICertName string
+
+	ErrAPIAppNameNotSet  error = errors.New("APIAppName is not set")
+	ErrAPIDevNameNotSet  error = errors.New("APIDevName is not set")
+	ErrAPICertNameNotSet error = errors.New("APICertName is not set")
+	ErrAPISiteIDNotSet   error = errors.New("APISiteID is not set")
+
+	// requiredHeaders are the headers every request has.
+	requiredHeaders = []string{"X-EBAY-API-CALL-NAME", "X-EBAY-API-SITEID", "X-EBAY-API-COMPATIBILITY-LEVEL"}
+)
+
+func (x *xbayRequester) request() error {
+	if x.siteID == "" {
+		return ErrAPISiteIDNotSet
+	}
+	if APIGateway == "" {
+		return ErrAPIGatewayNotSet
+	}
+	request, err := http.NewRequestWithContext(x.ctx, "POST", APIGateway, x.body)
+	if err != nil {
+		return err
+	}
+
+	switch x.callName {
+	case "GetSessionID", "FetchToken", "GetTokenStatus", "RevokeToken":
+		if APIDevName == "" {
+			return ErrAPIDevNameNotSet
+		}
+		if APIAppName == "" {
+			return ErrAPIAppNameNotSet
+		}
+		if APICertName == "" {
+			return ErrAPICertNameNotSet
+		}
+		request.Header.Add("X-EBAY-API-DEV-NAME", APIDevName)
+		request.Header.Add("X-EBAY-API-APP-NAME", APIAppName)
+		request.Header.Add("X-EBAY-API-CERT-NAME", APICertName)
+	}
+	request.Header.Add("X-EBAY-API-COMPATIBILITY-LEVEL", APICompatibilityLevel)
+	request.Header.Add("X-EBAY-API-SITEID", x.siteID)
+	request.Header.Add("X-EBAY-API-CALL-NAME", x.callName)
+
+	return x.send(request)
+}
+`
+
+var templateShoppingRuntime = `
+var (
+	APIGateway string = "https://open.api.ebay.com/shopping"
+
+	// X-EBAY-API-VERSION
+	// Required: Always
+	// The API version your application supports.
+	APIVersion string = "%[1]s"
+
+	// X-EBAY-API-APP-ID
+	// Required: Always
+	// Your application ID (AppID), as registered with the eBay Developers Program.
+	APIAppID string
+
+	ErrAPIAppIDNotSet error = errors.New("APIAppID is not set")
+
+	// requiredHeaders are the headers every request has.
+	requiredHeaders = []string{"X-EBAY-API-CALL-NAME", "X-EBAY-API-APP-ID", "X-EBAY-API-VERSION"}
+)
+
+// request sends the call to the site given by the siteID (X-EBAY-API-SITE-ID),
+// the US site if empty.
+func (x *xbayRequester) request() error {
+	if APIGateway == "" {
+		return ErrAPIGatewayNotSet
+	}
+	if APIAppID == "" {
+		return ErrAPIAppIDNotSet
+	}
+	request, err := http.NewRequestWithContext(x.ctx, "POST", APIGateway, x.body)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("X-EBAY-API-APP-ID", APIAppID)
+	request.Header.Add("X-EBAY-API-VERSION", APIVersion)
+	request.Header.Add("X-EBAY-API-CALL-NAME", x.callName)
+	request.Header.Add("X-EBAY-API-REQUEST-ENCODING", "XML")
+	if x.siteID != "" {
+		request.Header.Add("X-EBAY-API-SITE-ID", x.siteID)
+	}
+
+	return x.send(request)
+}
+`
+
+// templateSOARuntime serves eBay's SOA APIs (Finding, Merchandising), which
+// differ in the header taking the application ID.
+var templateSOARuntime = `
+var (
+	APIGateway string = %[3]q
+
+	// X-EBAY-SOA-SERVICE-VERSION
+	// Required: No
+	// The version of the service your application supports.
+	APIVersion string = "%[1]s"
+
+	// %[2]s
+	// Required: Always
+	// Your application ID (AppID), as registered with the eBay Developers Program.
+	APIAppID string
+
+	ErrAPIAppIDNotSet error = errors.New("APIAppID is not set")
+
+	// requiredHeaders are the headers every request has.
+	requiredHeaders = []string{"X-EBAY-SOA-OPERATION-NAME", "%[2]s"}
+)
+
+// request sends the call to the site given by the siteID, a global ID like
+// EBAY-US (X-EBAY-SOA-GLOBAL-ID), the US site if empty.
+func (x *xbayRequester) request() error {
+	if APIGateway == "" {
+		return ErrAPIGatewayNotSet
+	}
+	if APIAppID == "" {
+		return ErrAPIAppIDNotSet
+	}
+	request, err := http.NewRequestWithContext(x.ctx, "POST", APIGateway, x.body)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("%[2]s", APIAppID)
+	request.Header.Add("X-EBAY-SOA-SERVICE-VERSION", APIVersion)
+	request.Header.Add("X-EBAY-SOA-OPERATION-NAME", x.callName)
+	request.Header.Add("X-EBAY-SOA-REQUEST-DATA-FORMAT", "XML")
+	if x.siteID != "" {
+		request.Header.Add("X-EBAY-SOA-GLOBAL-ID", x.siteID)
+	}
+
+	return x.send(request)
+}
+`
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type protoKind byte
+
+const (
+	protoScalar protoKind = iota
+	protoNull
+	protoEnum
+	protoMessage
+)
+
+// protoField is a struct field of a generated type as written to -proto.
+type protoField struct {
+	Name      string // proto field name
+	GoName    string // generated struct field
+	PbName    string // struct field generated by protoc-gen-go
+	Kind      protoKind
+	ProtoType string // scalar, enum or message name
+	GoType    string // Go type of the value, without slice or pointer
+	Repeated  bool
+	Pointer   bool
+}
+
+// protoScalars maps Go types to proto scalar types.
+var protoScalars = map[string]string{
+	"string":  "string",
+	"int64":   "int64",
+	"int32":   "int32",
+	"float64": "double",
+	"float32": "float",
+	"bool":    "bool",
+	"[]byte":  "bytes",
+}
+
+// protoGoTypes maps proto scalar types to the Go types protoc-gen-go uses.
+var protoGoTypes = map[string]string{
+	"string": "string",
+	"int64":  "int64",
+	"int32":  "int32",
+	"double": "float64",
+	"float":  "float32",
+	"bool":   "bool",
+	"bytes":  "[]byte",
+}
+
+// pbReservedNames are the struct field names protoc-gen-go renames, adding
+// an underscore, as they clash with generated methods.
+var pbReservedNames = map[string]bool{
+	"Reset":               true,
+	"String":              true,
+	"ProtoMessage":        true,
+	"Marshal":             true,
+	"Unmarshal":           true,
+	"ExtensionRangeArray": true,
+	"ExtensionMap":        true,
+	"Descriptor":          true,
+}
+
+var protoInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// newProtoField returns the proto field of x, a field of a generated struct.
+func newProtoField(x Xyer) (f protoField, ok bool) {
+	var goType string
+	switch t := x.(type) {
+	case element:
+		goType = t.TransformType()
+	case attribute:
+		goType = t.TransformType()
+	case *extensionSimpleContent:
+		goType = t.GetType().GoType()
+	default:
+		return f, false
+	}
+	f.Name = ToSnake(x.GetName())
+	f.GoName = UpperFirstLetter(x.GetName())
+	f.PbName = GoCamelCase(f.Name)
+	if pbReservedNames[f.PbName] {
+		f.PbName += "_"
+	}
+
+	for base, list := range SliceableType {
+		if goType == list {
+			goType = base
+			f.Repeated = true
+		}
+	}
+	if strings.HasPrefix(goType, "[]") && goType != "[]byte" {
+		goType = strings.TrimPrefix(goType, "[]")
+		f.Repeated = true
+	}
+	if strings.HasPrefix(goType, "*") {
+		goType = strings.TrimPrefix(goType, "*")
+		f.Pointer = true
+	}
+	f.GoType = goType
+
+	if strings.HasPrefix(goType, "Null") {
+		f.Kind = protoNull
+		f.ProtoType, ok = protoScalars[strings.ToLower(strings.TrimPrefix(goType, "Null"))]
+		return f, ok
+	}
+	if f.ProtoType, ok = protoScalars[goType]; ok {
+		return f, true
+	}
+	if _, ok = FindComplex(goType); ok {
+		f.Kind, f.ProtoType = protoMessage, goType
+		return f, true
+	}
+	if s, ok := FindSimple(goType); ok {
+		base := protoSimpleBase(s)
+		if len(s.Values()) > 0 && base == "string" {
+			f.Kind, f.ProtoType = protoEnum, goType
+			return f, true
+		}
+		f.ProtoType, ok = protoScalars[base]
+		return f, ok
+	}
+	return f, false
+}
+
+// protoSimpleBase returns the Go type a simple type is based on.
+func protoSimpleBase(s *simpleType) string {
+	for i := 0; i < 10; i++ {
+		if s.GetType().IsXS() {
+			break
+		}
+		next, ok := FindSimple(s.GetType().String())
+		if !ok {
+			break
+		}
+		s = next
+	}
+	return s.GetType().GoType(true)
+}
+
+// Declaration returns the field declaration of the .proto message, numbered n.
+// Null* fields and pointers to scalars are optional, keeping whether they were
+// set; slices are repeated.
+func (f protoField) Declaration(n int) string {
+	label := ""
+	if f.Repeated {
+		label = "repeated "
+	} else if f.Kind == protoNull || (f.Pointer && f.Kind == protoScalar) {
+		label = "optional "
+	}
+	return fmt.Sprintf("%s%s %s = %d;", label, f.ProtoType, f.Name, n)
+}
+
+// ToProto returns the statements copying the field from x to p.
+func (f protoField) ToProto() string {
+	from, to := "x."+f.GoName, "p."+f.PbName
+	if f.Repeated {
+		return fmt.Sprintf("for i := range %[1]s {\r\n%[2]s = append(%[2]s, %[3]s)\r\n}\r\n", from, to, f.toProtoValue(from+"[i]"))
+	}
+	switch {
+	case f.Kind == protoNull:
+		return fmt.Sprintf("if %[1]s.Valid {\r\nv := %[1]s.Value()\r\n%[2]s = &v\r\n}\r\n", from, to)
+	case f.Kind == protoMessage && f.Pointer:
+		return fmt.Sprintf("%s = %s.ToProto()\r\n", to, from)
+	case f.Pointer:
+		return fmt.Sprintf("if %[1]s != nil {\r\nv := %[3]s\r\n%[2]s = &v\r\n}\r\n", from, to, f.toProtoValue("*"+from))
+	}
+	return fmt.Sprintf("%s = %s\r\n", to, f.toProtoValue(from))
+}
+
+func (f protoField) toProtoValue(v string) string {
+	switch f.Kind {
+	case protoNull:
+		return v + ".Value()"
+	case protoEnum:
+		return protoEnumMap(f.GoType, true) + "[" + v + "]"
+	case protoMessage:
+		return v + ".ToProto()"
+	}
+	return protoGoTypes[f.ProtoType] + "(" + v + ")"
+}
+
+// FromProto returns the statements copying the field from p to x.
+func (f protoField) FromProto() string {
+	from, to := "p."+f.PbName, "x."+f.GoName
+	if f.Repeated {
+		var add string
+		switch f.Kind {
+		case protoNull:
+			add = fmt.Sprintf("var n %s\r\nn.Set(v)\r\n%[2]s = append(%[2]s, n)", f.GoType, to)
+		case protoMessage:
+			add = fmt.Sprintf("if v != nil {\r\n%[1]s = append(%[1]s, *%[2]sFromProto(v))\r\n}", to, f.GoType)
+		default:
+			add = fmt.Sprintf("%[1]s = append(%[1]s, %[2]s)", to, f.fromProtoValue("v"))
+		}
+		return fmt.Sprintf("for _, v := range %s {\r\n%s\r\n}\r\n", from, add)
+	}
+	switch {
+	case f.Kind == protoNull:
+		return fmt.Sprintf("if %[1]s != nil {\r\n%[2]s.Set(*%[1]s)\r\n}\r\n", from, to)
+	case f.Kind == protoMessage && f.Pointer:
+		return fmt.Sprintf("%s = %sFromProto(%s)\r\n", to, f.GoType, from)
+	case f.Kind == protoMessage:
+		return fmt.Sprintf("if %[1]s != nil {\r\n%[2]s = *%[3]sFromProto(%[1]s)\r\n}\r\n", from, to, f.GoType)
+	case f.Pointer:
+		return fmt.Sprintf("if %[1]s != nil {\r\nv := %[3]s\r\n%[2]s = &v\r\n}\r\n", from, to, f.fromProtoValue("*"+from))
+	}
+	return fmt.Sprintf("%s = %s\r\n", to, f.fromProtoValue(from))
+}
+
+func (f protoField) fromProtoValue(v string) string {
+	if f.Kind == protoEnum {
+		return protoEnumMap(f.GoType, false) + "[" + v + "]"
+	}
+	return f.GoType + "(" + v + ")"
+}
+
+// protoEnumMap returns the name of the map converting the enum to or from
+// its protoc-gen-go type.
+func protoEnumMap(enum string, toProto bool) string {
+	if toProto {
+		return LowerFirstLetter(enum) + "ToProto"
+	}
+	return LowerFirstLetter(enum) + "FromProto"
+}
+
+// protoEnumValues returns the proto value names of a CodeType's values,
+// prefixed with the enum name as proto3 enum values share the package scope:
+// CurrencyCodeType USD is CURRENCY_CODE_TYPE_USD. The zero value is
+// <PREFIX>_UNSPECIFIED and stands for an unset field.
+func protoEnumValues(enum string, values []string) (unspecified string, names []string) {
+	prefix := strings.ToUpper(ToSnake(enum)) + "_"
+	unspecified = prefix + "UNSPECIFIED"
+	used := map[string]bool{unspecified: true}
+	for _, v := range values {
+		base := prefix + strings.ToUpper(strings.Trim(protoInvalidChars.ReplaceAllString(ToSnake(v), "_"), "_"))
+		name := base
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[name] = true
+		names = append(names, name)
+	}
+	return
+}
+
+// GoCamelCase returns the Go name protoc-gen-go gives to a proto identifier.
+func GoCamelCase(s string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isLower(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+		case isDigit(c):
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// protoTypes returns the generated CodeTypes and struct types, sorted.
+func protoTypes() (enums []*simpleType, messages []*complexType) {
+	var names []string
+	for name := range Enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if x, ok := FindSimple(name); ok && len(x.Values()) > 0 && protoSimpleBase(x) == "string" {
+			enums = append(enums, x)
+		}
+	}
+
+	names = names[:0]
+	for name := range Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c, ok := FindComplex(name); ok {
+			messages = append(messages, c)
+		}
+	}
+	return
+}
+
+// protoFields returns the proto fields of a generated struct type, logging
+// the fields that cannot be written with mode, the output being written
+// ("Protocol Buffers", "SQL").
+func protoFields(c *complexType, mode string) (fields []protoField, xs []Xyer) {
+	for _, x := range c.GetElements() {
+		f, ok := newProtoField(x)
+		if !ok {
+			log.Printf("%s: no type for %s.%s, skipping", mode, c.GetName(), x.GetName())
+			continue
+		}
+		fields = append(fields, f)
+		xs = append(xs, x)
+	}
+	return
+}
+
+// writeProto writes proto3 definitions of the generated types to -proto:
+// a message per struct and an enum per CodeType. Fields are numbered in
+// schema order, so numbers may change when the schema does.
+func writeProto() {
+	if *protoFile == "" {
+		return
+	}
+	enums, messages := protoTypes()
+
+	b := NewBuffer()
+	b.Sprintf("// Code generated by xsdbay. DO NOT EDIT.\n// eBay API version %s\n\n", *apiVersion)
+	b.Sprintf("syntax = \"proto3\";\n\npackage %s;\n", *protoPackage)
+	if *protoGoPackage != "" {
+		b.Sprintf("\noption go_package = %q;\n", *protoGoPackage)
+	}
+
+	for _, x := range enums {
+		unspecified, names := protoEnumValues(x.GetName(), x.Values())
+		b.Sprintf("\n%senum %s {\n  %s = 0;\n", protoComment(description(&x.Annotation), ""), x.GetName(), unspecified)
+		for i, name := range names {
+			b.Sprintf("  %s = %d;\n", name, i+1)
+		}
+		b.Sprintf("}\n")
+	}
+
+	for _, c := range messages {
+		b.Sprintf("\n%smessage %s {\n", protoComment(description(&c.Annotation), ""), c.GetName())
+		fields, xs := protoFields(c, "Protocol Buffers")
+		for i, f := range fields {
+			_, a, _ := fieldDetails(xs[i])
+			b.Sprintf("%s  %s\n", protoComment(description(a), "  "), f.Declaration(i+1))
+		}
+		b.Sprintf("}\n")
+	}
+
+	if err := ioutil.WriteFile(*protoFile, b.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func protoComment(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	return indent + "// " + text + "\n"
+}
+
+// writeProtoConverters writes to -proto-go the functions converting the
+// generated types to and from the types protoc-gen-go generates from -proto:
+// (*T).ToProto() and TFromProto(). CodeType values missing from the .proto
+// convert to the UNSPECIFIED value.
+func writeProtoConverters() {
+	if *protoGoFile == "" {
+		return
+	}
+	if *protoGoPackage == "" {
+		log.Fatal("-proto-go needs the Go import path of the generated proto package, set -proto-go-package")
+	}
+	enums, messages := protoTypes()
+
+	b := NewBuffer()
+	b.Sprintf("// Code generated by xsdbay. DO NOT EDIT.\r\n\r\npackage ebaysvc\r\n\r\nimport pb %q\r\n", *protoGoPackage)
+
+	for _, x := range enums {
+		pbName := GoCamelCase(x.GetName())
+		unspecified, names := protoEnumValues(x.GetName(), x.Values())
+		b.Sprintf("\r\nvar %s = map[%s]pb.%s{\r\n\"\": pb.%[3]s_%[4]s,\r\n", protoEnumMap(x.GetName(), true), x.GetName(), pbName, unspecified)
+		for i, v := range x.Values() {
+			b.Sprintf("%q: pb.%s_%s,\r\n", v, pbName, names[i])
+		}
+		b.Sprintf("}\r\n\r\nvar %s = map[pb.%s]%s{\r\n", protoEnumMap(x.GetName(), false), pbName, x.GetName())
+		for i, v := range x.Values() {
+			b.Sprintf("pb.%s_%s: %q,\r\n", pbName, names[i], v)
+		}
+		b.Sprintf("}\r\n")
+	}
+
+	for _, c := range messages {
+		name := c.GetName()
+		pbName := GoCamelCase(name)
+		fields, _ := protoFields(c, "Protocol Buffers")
+
+		b.Sprintf("\r\n// ToProto converts x to its Protocol Buffers message.\r\n")
+		b.Sprintf("func (x *%s) ToProto() *pb.%s {\r\nif x == nil {\r\nreturn nil\r\n}\r\np := &pb.%[2]s{}\r\n", name, pbName)
+		for _, f := range fields {
+			b.Sprintf("%s", f.ToProto())
+		}
+		b.Sprintf("return p\r\n}\r\n")
+
+		b.Sprintf("\r\n// %sFromProto converts a Protocol Buffers message to %[1]s.\r\n", name)
+		b.Sprintf("func %sFromProto(p *pb.%s) *%[1]s {\r\nif p == nil {\r\nreturn nil\r\n}\r\nx := &%[1]s{}\r\n", name, pbName)
+		for _, f := range fields {
+			b.Sprintf("%s", f.FromProto())
+		}
+		b.Sprintf("return x\r\n}\r\n")
+	}
+
+	if err := ioutil.WriteFile(*protoGoFile, formatCode(b.Bytes()), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_protoEnumValues(t *testing.T) {
+	tests := []struct {
+		enum        string
+		values      []string
+		unspecified string
+		names       []string
+	}{
+		{"CurrencyCodeType", []string{"USD", "EUR"}, "CURRENCY_CODE_TYPE_UNSPECIFIED", []string{"CURRENCY_CODE_TYPE_USD", "CURRENCY_CODE_TYPE_EUR"}},
+		{"ListingTypeCodeType", []string{"Chinese", "FixedPriceItem", "CustomCode"}, "LISTING_TYPE_CODE_TYPE_UNSPECIFIED", []string{"LISTING_TYPE_CODE_TYPE_CHINESE", "LISTING_TYPE_CODE_TYPE_FIXED_PRICE_ITEM", "LISTING_TYPE_CODE_TYPE_CUSTOM_CODE"}},
+		// Characters proto identifiers cannot hold become underscores, names
+		// clashing after that are numbered.
+		{"LanguageCodeType", []string{"en-US", "en_US", "de.DE"}, "LANGUAGE_CODE_TYPE_UNSPECIFIED", []string{"LANGUAGE_CODE_TYPE_EN_US", "LANGUAGE_CODE_TYPE_EN_US_2", "LANGUAGE_CODE_TYPE_DE_DE"}},
+		// A value named like the zero value does not take its name.
+		{"StatusCodeType", []string{"Unspecified"}, "STATUS_CODE_TYPE_UNSPECIFIED", []string{"STATUS_CODE_TYPE_UNSPECIFIED_2"}},
+	}
+	for _, tt := range tests {
+		unspecified, names := protoEnumValues(tt.enum, tt.values)
+		if unspecified != tt.unspecified {
+			t.Errorf("protoEnumValues(%s) unspecified = %s, want %s", tt.enum, unspecified, tt.unspecified)
+		}
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("protoEnumValues(%s, %q) = %q, want %q", tt.enum, tt.values, names, tt.names)
+		}
+	}
+}
+
+// The cases of protoc-gen-go's own GoCamelCase tests.
+func Test_GoCamelCase(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"one", "One"},
+		{"one_two", "OneTwo"},
+		{"_my_field_name_2", "XMyFieldName_2"},
+		{"Something_Capped", "Something_Capped"},
+		{"my_Name", "My_Name"},
+		{"OneTwo", "OneTwo"},
+		{"_", "X"},
+		{"_a_", "XA_"},
+		{"one.two", "OneTwo"},
+		{"one.Two", "One_Two"},
+		{"one_two.three_four", "OneTwoThreeFour"},
+		{"one_two.Three_four", "OneTwo_ThreeFour"},
+		{"_one._two", "XOne_XTwo"},
+		{"SCREAMING_SNAKE_CASE", "SCREAMING_SNAKE_CASE"},
+		{"double__underscore", "Double_Underscore"},
+		{"camelCase", "CamelCase"},
+		{"go2proto", "Go2Proto"},
+		{"ebay_auth_token", "EbayAuthToken"},
+	}
+	for _, tt := range tests {
+		if got := GoCamelCase(tt.in); got != tt.want {
+			t.Errorf("GoCamelCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
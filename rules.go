@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rules holds the validation rules read with -rules (JSON, or YAML for
+// .yaml/.yml files), by call name and field path below the request type
+// (Item.Quantity):
+//
+//	{
+//	  "AddItem": {
+//	    "Item.Quantity": {"requiredIf": [{"field": "Item.ListingType", "values": ["FixedPriceItem"]}]},
+//	    "Item.Title": {"maxLength": 65},
+//	    "Item.SKU": {"suppress": ["MaxLength"]}
+//	  }
+//	}
+var Rules = map[string]map[string]FieldRules{}
+
+// FieldRules add to, override or suppress (by ValidationType name, e.g.
+// "MaxLength") the rules generated from the schema for one field.
+type FieldRules struct {
+	Required      *bool       `json:"required,omitempty"`
+	RequiredIf    []Condition `json:"requiredIf,omitempty"`
+	MaxLength     *int        `json:"maxLength,omitempty"`
+	Min           *float64    `json:"min,omitempty"`
+	Max           *float64    `json:"max,omitempty"`
+	MinOccurs     *int        `json:"minOccurs,omitempty"`
+	MaxOccurs     *int        `json:"maxOccurs,omitempty"`
+	AllowedValues []string    `json:"allowedValues,omitempty"`
+	Suppress      []string    `json:"suppress,omitempty"`
+}
+
+// Apply merges the field's rules into list, the rules generated from the
+// schema.
+func (r FieldRules) Apply(list ValidationContainer) ValidationContainer {
+	requiredRule := r.Required
+	for _, name := range r.Suppress {
+		validation := validationTypeByName(name)
+		list = list.Except(validation)
+		// Suppressing Required makes the field optional, like "required": false.
+		if validation == ValTypRequired && requiredRule == nil {
+			requiredRule = new(bool)
+		}
+	}
+	if requiredRule != nil {
+		list = list.Except(ValTypRequired)
+		if *requiredRule {
+			list.New(ValTypRequired, nil)
+		}
+		for i := range list {
+			list[i].IfSet = !*requiredRule
+		}
+	}
+	_, required := list.Includes(ValTypRequired)
+
+	set := func(validation ValidationType, value interface{}) {
+		list = list.Except(validation)
+		list = append(list, ValidationRule{Type: validation, Value: value, IfSet: !required})
+	}
+	if r.MaxLength != nil {
+		set(ValTypMaxLength, strconv.Itoa(*r.MaxLength))
+	}
+	if r.Min != nil {
+		set(ValTypMin, fmt.Sprint(*r.Min))
+	}
+	if r.Max != nil {
+		set(ValTypMax, fmt.Sprint(*r.Max))
+	}
+	if r.MinOccurs != nil {
+		set(ValTypMinOccurs, *r.MinOccurs)
+	}
+	if r.MaxOccurs != nil {
+		set(ValTypMaxOccurs, *r.MaxOccurs)
+	}
+	if len(r.AllowedValues) > 0 {
+		set(ValTypOnlyTheseValues, strings.Join(r.AllowedValues, ","))
+		list = list.Except(ValTypAllValuesExcept, ValTypEnumeration)
+	}
+	return list
+}
+
+// fieldRules returns the rules read with -rules for the field at fieldPath.
+func fieldRules(callName, fieldPath string) FieldRules {
+	return Rules[callName][fieldPath]
+}
+
+// hasRules reports whether -rules holds rules for the field at fieldPath in
+// callName or for a field below it.
+func hasRules(callName, fieldPath string) bool {
+	for rulePath := range Rules[callName] {
+		if rulePath == fieldPath || strings.HasPrefix(rulePath, fieldPath+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func validationTypeByName(name string) ValidationType {
+	for t, n := range validationTypeNames {
+		if n == name {
+			return t
+		}
+	}
+	log.Fatalf("unknown validation type: %s", name)
+	return 0
+}
+
+// Condition is met when the field at path Field holds one of Values.
+type Condition struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+}
+
+func (c Condition) String() string {
+	return c.Field + " is " + strings.Join(c.Values, " or ")
+}
+
+// requiredIf is the value of a ValTypRequiredIf rule: the condition and the
+// Go expression testing it.
+type requiredIf struct {
+	Condition Condition
+	Expr      string
+}
+
+func loadRules() {
+	if *rulesFile == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(*rulesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ext := strings.ToLower(filepath.Ext(*rulesFile)); ext == ".yaml" || ext == ".yml" {
+		if data, err = yamlToJSON(data); err != nil {
+			log.Fatalf("could not read rules from %s: %s", *rulesFile, err)
+		}
+	}
+	if err = json.Unmarshal(data, &Rules); err != nil {
+		log.Fatalf("could not read rules from %s: %s", *rulesFile, err)
+	}
+	for _, fields := range Rules {
+		for _, r := range fields {
+			for _, name := range r.Suppress {
+				validationTypeByName(name)
+			}
+		}
+	}
+}
+
+var (
+	loopIndex = regexp.MustCompile(`\[[^\]]*\]`)
+
+	// "Required if ListingType is FixedPriceItem or StoresFixedPrice."
+	requiredIfText = regexp.MustCompile(`(?i)required (?:only )?(?:if|when) (?:the )?(\w+)(?: field)? is (?:set to )?(\w+(?:(?:, or|,| or) \w+)*)`)
+	conditionOr    = regexp.MustCompile(`,? or |, `)
+)
+
+// conditionValues returns the values of a requiredIfText match. The list
+// ends at ", and", which starts the next clause: "is Chinese, and the item
+// has a reserve price".
+func conditionValues(list string) (values []string) {
+	for _, value := range conditionOr.Split(list, -1) {
+		if strings.EqualFold(value, "and") {
+			break
+		}
+		values = append(values, value)
+	}
+	return
+}
+
+// NormalisePath turns the Go path of a field (x.Item.PictureURL[i123]) into
+// the path used by Rules (Item.PictureURL).
+func NormalisePath(path string) string {
+	path = loopIndex.ReplaceAllString(path, "")
+	if path == "x" {
+		return ""
+	}
+	return strings.TrimPrefix(path, "x.")
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// Conditions returns the conditions making the element required in the
+// request of callName. parent is the normalised path of the element's parent.
+// Conditions come from Rules and from the appinfo, see
+// AppInfoExtension.RequiredIf.
+func (e element) Conditions(callName, parent string) (list []Condition) {
+	fieldPath := joinPath(parent, UpperFirstLetter(e.GetName()))
+	if r, ok := Rules[callName][fieldPath]; ok {
+		list = append(list, r.RequiredIf...)
+	}
+	return append(list, appInfoExt.RequiredIf(e.Annotation, callName, parent)...)
+}
+
+// HasConditions reports whether Conditions may return anything for the
+// element, without knowing its path.
+func (e element) HasConditions(callName string) bool {
+	name := UpperFirstLetter(e.GetName())
+	for fieldPath, r := range Rules[callName] {
+		if len(r.RequiredIf) > 0 && (fieldPath == name || strings.HasSuffix(fieldPath, "."+name)) {
+			return true
+		}
+	}
+	return len(e.Conditions(callName, "")) > 0
+}
+
+// conditionExpr returns the Go condition testing c inside the Validate()
+// method of callName, where path is the Go path of the type being validated.
+// Pointers not shared with path are checked for nil first.
+func conditionExpr(callName, path string, c Condition) (string, error) {
+	current, ok := FindComplex(callName + "RequestType")
+	if !ok {
+		return "", fmt.Errorf("could not find %sRequestType", callName)
+	}
+	goPath := strings.Split(path, ".")[1:]
+	shared := true
+	expr := "x"
+	var conditions []string
+	var details *TypeDetails
+
+	segments := strings.Split(c.Field, ".")
+	for i, segment := range segments {
+		var field Xyer
+		for _, x := range current.GetElements() {
+			if UpperFirstLetter(x.GetName()) == segment {
+				field = x
+			}
+		}
+		if field == nil {
+			return "", fmt.Errorf("%s has no field %s", current.GetName(), segment)
+		}
+		switch x := field.(type) {
+		case element:
+			details = x.TypeDetails()
+		case attribute:
+			details = x.TypeDetails()
+		default:
+			return "", fmt.Errorf("unsupported field %s", segment)
+		}
+
+		if shared && i < len(goPath) && NormalisePath("x."+goPath[i]) == segment {
+			expr += "." + goPath[i]
+		} else {
+			shared = false
+			if details.IsSlice {
+				return "", fmt.Errorf("%s is a repeated field", segment)
+			}
+			expr += "." + segment
+			if details.IsPointer && i < len(segments)-1 {
+				conditions = append(conditions, expr+" != nil")
+			}
+		}
+
+		if i < len(segments)-1 {
+			if current, ok = FindComplex(field.GetType().String()); !ok {
+				return "", fmt.Errorf("%s is not a complex type", segment)
+			}
+		}
+	}
+	if details.IsPointer || details.IsSlice {
+		return "", fmt.Errorf("%s is not a simple field", c.Field)
+	}
+
+	value := details.StringValue(expr)
+	var values []string
+	for _, v := range c.Values {
+		values = append(values, fmt.Sprintf("%s == %q", value, v))
+	}
+	if len(values) > 1 {
+		conditions = append(conditions, "("+strings.Join(values, " || ")+")")
+	} else {
+		conditions = append(conditions, values...)
+	}
+	return strings.Join(conditions, " && "), nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_requiredIfText(t *testing.T) {
+	tests := []struct {
+		text   string
+		field  string
+		values []string
+	}{
+		{"Required if ListingType is FixedPriceItem.", "ListingType", []string{"FixedPriceItem"}},
+		{"Required if ListingType is Chinese, and the seller sets a reserve price.", "ListingType", []string{"Chinese"}},
+		{"This field is required when the ListingType field is set to FixedPriceItem or StoresFixedPrice.", "ListingType", []string{"FixedPriceItem", "StoresFixedPrice"}},
+		{"Required only if PaymentMethods is PayPal, CreditCard, or Moneybookers; ignored otherwise.", "PaymentMethods", []string{"PayPal", "CreditCard", "Moneybookers"}},
+		{"Required if the ShippingType is Flat, Calculated or FreightFlat", "ShippingType", []string{"Flat", "Calculated", "FreightFlat"}},
+		{"Not applicable to Half.com.", "", nil},
+		{"Required for multiple-variation listings.", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			m := requiredIfText.FindStringSubmatch(tt.text)
+			if len(m) != 3 {
+				if tt.field != "" {
+					t.Fatalf("requiredIfText did not match, want %s is %v", tt.field, tt.values)
+				}
+				return
+			}
+			if tt.field == "" {
+				t.Fatalf("requiredIfText matched %q, want no match", m[0])
+			}
+			if m[1] != tt.field {
+				t.Errorf("field = %q, want %q", m[1], tt.field)
+			}
+			if got := conditionValues(m[2]); !reflect.DeepEqual(got, tt.values) {
+				t.Errorf("conditionValues(%q) = %q, want %q", m[2], got, tt.values)
+			}
+		})
+	}
+}
+
+const allCalls = `<xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation>`
+
+const conditionSchema = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ns="urn:ebay:apis:eBLBaseComponents" targetNamespace="urn:ebay:apis:eBLBaseComponents" elementFormDefault="qualified">
+<xs:element name="AddItemRequest" type="ns:AddItemRequestType"/>
+<xs:complexType name="AddItemRequestType"><xs:sequence>
+ <xs:element name="Item" type="ns:ItemType" minOccurs="0">` + allCalls + `</xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="ItemType"><xs:sequence>
+ <xs:element name="ListingType" type="ns:ListingTypeCodeType" minOccurs="0">` + allCalls + `</xs:element>
+ <xs:element name="PictureURL" type="xs:anyURI" minOccurs="0" maxOccurs="unbounded">` + allCalls + `</xs:element>
+ <xs:element name="ShippingDetails" type="ns:ShippingDetailsType" minOccurs="0">` + allCalls + `</xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="ShippingDetailsType"><xs:sequence>
+ <xs:element name="ShippingType" type="xs:token" minOccurs="0">` + allCalls + `</xs:element>
+</xs:sequence></xs:complexType>
+<xs:simpleType name="ListingTypeCodeType"><xs:restriction base="xs:token">
+ <xs:enumeration value="Chinese"/><xs:enumeration value="FixedPriceItem"/>
+</xs:restriction></xs:simpleType>
+</xs:schema>`
+
+func Test_conditionExpr(t *testing.T) {
+	loadSchema(t, conditionSchema, "AddItem")
+
+	tests := []struct {
+		path    string
+		field   string
+		values  []string
+		want    string
+		wantErr bool
+	}{
+		{"x.Item", "Item.ListingType", []string{"FixedPriceItem"}, `string(x.Item.ListingType) == "FixedPriceItem"`, false},
+		{"x.Item.ShippingDetails", "Item.ListingType", []string{"Chinese"}, `string(x.Item.ListingType) == "Chinese"`, false},
+		{"x", "Item.ShippingDetails.ShippingType", []string{"Flat", "Calculated"}, `x.Item != nil && x.Item.ShippingDetails != nil && (x.Item.ShippingDetails.ShippingType.String() == "Flat" || x.Item.ShippingDetails.ShippingType.String() == "Calculated")`, false},
+		{"x.Item", "Item.Title", []string{"x"}, "", true},
+		{"x.Item", "Item.ShippingDetails", []string{"x"}, "", true},
+		{"x", "Item.PictureURL", []string{"x"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+":"+tt.field, func(t *testing.T) {
+			got, err := conditionExpr("AddItem", tt.path, Condition{Field: tt.field, Values: tt.values})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("conditionExpr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("conditionExpr() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_FieldRules_Apply(t *testing.T) {
+	yes, no := true, false
+	maxLength, min := 65, 0.99
+	schema := ValidationContainer{
+		{Type: ValTypRequired},
+		{Type: ValTypMaxLength, Value: "80"},
+		{Type: ValTypEnumeration, Value: "A,B"},
+	}
+	optional := ValidationContainer{
+		{Type: ValTypMaxLength, Value: "80", IfSet: true},
+	}
+	tests := []struct {
+		name  string
+		rules FieldRules
+		list  ValidationContainer
+		want  ValidationContainer
+	}{
+		{"no rules", FieldRules{}, schema, schema},
+		{"override", FieldRules{MaxLength: &maxLength}, schema, ValidationContainer{
+			{Type: ValTypRequired},
+			{Type: ValTypEnumeration, Value: "A,B"},
+			{Type: ValTypMaxLength, Value: "65"},
+		}},
+		{"not required", FieldRules{Required: &no}, schema, ValidationContainer{
+			{Type: ValTypMaxLength, Value: "80", IfSet: true},
+			{Type: ValTypEnumeration, Value: "A,B", IfSet: true},
+		}},
+		{"suppress required", FieldRules{Suppress: []string{"Required"}}, schema, ValidationContainer{
+			{Type: ValTypMaxLength, Value: "80", IfSet: true},
+			{Type: ValTypEnumeration, Value: "A,B", IfSet: true},
+		}},
+		{"suppress required, still required", FieldRules{Required: &yes, Suppress: []string{"Required"}}, schema, ValidationContainer{
+			{Type: ValTypMaxLength, Value: "80"},
+			{Type: ValTypEnumeration, Value: "A,B"},
+			{Type: ValTypRequired},
+		}},
+		{"required", FieldRules{Required: &yes}, optional, ValidationContainer{
+			{Type: ValTypMaxLength, Value: "80"},
+			{Type: ValTypRequired},
+		}},
+		{"new rule on optional field", FieldRules{Min: &min}, optional, ValidationContainer{
+			{Type: ValTypMaxLength, Value: "80", IfSet: true},
+			{Type: ValTypMin, Value: "0.99", IfSet: true},
+		}},
+		{"allowed values", FieldRules{AllowedValues: []string{"A"}}, schema, ValidationContainer{
+			{Type: ValTypRequired},
+			{Type: ValTypMaxLength, Value: "80"},
+			{Type: ValTypOnlyTheseValues, Value: "A"},
+		}},
+		{"suppress", FieldRules{Suppress: []string{"MaxLength", "Enumeration"}}, schema, ValidationContainer{
+			{Type: ValTypRequired},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := append(ValidationContainer{}, tt.list...)
+			if got := tt.rules.Apply(list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hasRules(t *testing.T) {
+	defer func() { Rules = map[string]map[string]FieldRules{} }()
+	Rules = map[string]map[string]FieldRules{
+		"AddItem": {"Item.ShippingDetails.Rate": {}},
+	}
+	tests := []struct {
+		call, path string
+		want       bool
+	}{
+		{"AddItem", "Item.ShippingDetails.Rate", true},
+		{"AddItem", "Item.ShippingDetails", true},
+		{"AddItem", "Item", true},
+		{"AddItem", "Item.StartPrice.Rate", false},
+		{"AddItem", "Rate", false},
+		{"AddItem", "Item.Shipping", false},
+		{"ReviseItem", "Item.ShippingDetails.Rate", false},
+	}
+	for _, tt := range tests {
+		if got := hasRules(tt.call, tt.path); got != tt.want {
+			t.Errorf("hasRules(%s, %s) = %v, want %v", tt.call, tt.path, got, tt.want)
+		}
+	}
+}
+
+func Test_yamlToJSON(t *testing.T) {
+	tests := []struct {
+		name, yaml, want string
+	}{
+		{"empty", "# no rules\n", `{}`},
+		{"rules", `
+AddItem:
+  Item.Title: {maxLength: 65}   # eBay's limit
+  Item.Quantity:
+    min: 0.99
+    required: false
+    suppress: [MaxOccurs, "Min"]
+    requiredIf:
+      - field: Item.ListingType
+        values:
+          - FixedPriceItem
+          - 'Stores#FixedPrice'
+`, `{"AddItem":{"Item.Quantity":{"min":0.99,"required":false,"requiredIf":[{"field":"Item.ListingType","values":["FixedPriceItem","Stores#FixedPrice"]}],"suppress":["MaxOccurs","Min"]},"Item.Title":{"maxLength":65}}}`},
+		{"sequence at key indentation", "a:\n- x\n- y\nb: []\n", `{"a":["x","y"],"b":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamlToJSON([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("yamlToJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"a: 1\n  b: 2\n", "a: [1, 2\n", "- a\nb: 1\n", "a: 1\na: 2\n", "a\n"} {
+		if _, err := yamlToJSON([]byte(bad)); err == nil {
+			t.Errorf("yamlToJSON(%q) did not fail", bad)
+		}
+	}
+}
+
+func Test_TypeDetails_Min_Max(t *testing.T) {
+	tests := []struct {
+		alias    Type
+		min, max float64
+		wantMin  string
+		wantMax  string
+	}{
+		{"xs:int", 0.5, 10.5, "x.Quantity.Value() < 1", "x.Quantity.Value() > 10"},
+		{"xs:int", 1, 10, "x.Quantity.Value() < 1", "x.Quantity.Value() > 10"},
+		{"xs:double", 0.99, 1e6, "x.Quantity.Value() < 0.99", "x.Quantity.Value() > 1000000"},
+		{"ns:AmountType", 0.99, 99.5, "x.Quantity.Value.Value() < 0.99", "x.Quantity.Value.Value() > 99.5"},
+	}
+	for _, tt := range tests {
+		details := TypeDetails{AliasFor: tt.alias}
+		if got := details.Min("x.Quantity", tt.min); got != tt.wantMin {
+			t.Errorf("%s Min(%v) = %s, want %s", tt.alias, tt.min, got, tt.wantMin)
+		}
+		if got := details.Max("x.Quantity", tt.max); got != tt.wantMax {
+			t.Errorf("%s Max(%v) = %s, want %s", tt.alias, tt.max, got, tt.wantMax)
+		}
+	}
+}
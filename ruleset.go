@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// RuleSet holds every rule written to a Validate() method, by call name and
+// field path (Item.PictureURL, Item.PictureURL[] for each element of a
+// repeated field). It is written with -rules-out.
+var RuleSet = map[string]map[string][]ExportedRule{}
+
+// ExportedRule is the JSON form of a ValidationRule. Value is a number,
+// a string, a list of allowed values or a condition, depending on Type.
+// IfSet rules do not apply to unset fields.
+type ExportedRule struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+	IfSet bool        `json:"ifSet,omitempty"`
+}
+
+// emitRule writes the check of rule to the Validate() method of callName and
+// records it in RuleSet.
+func emitRule(callName string, t *TypeDetails, rule ValidationRule, path string) {
+	check := t.ValidationString(rule, path)
+	if check == "" {
+		return
+	}
+	Validator[callName].Sprintf("%s", check)
+
+	fieldPath := strings.TrimPrefix(loopIndex.ReplaceAllString(path, "[]")+"."+UpperFirstLetter(t.Field), "x.")
+	if t.key != "" {
+		fieldPath += "[]"
+	}
+	if _, ok := RuleSet[callName]; !ok {
+		RuleSet[callName] = map[string][]ExportedRule{}
+	}
+	RuleSet[callName][fieldPath] = append(RuleSet[callName][fieldPath], ExportedRule{
+		Type:  rule.Type.String(),
+		Value: exportedValue(rule),
+		IfSet: rule.SkipsUnset(),
+	})
+}
+
+func exportedValue(rule ValidationRule) interface{} {
+	switch rule.Type {
+	case ValTypMaxLength:
+		v, err := rule.ValueInt()
+		if err != nil {
+			return nil
+		}
+		return v
+	case ValTypMin, ValTypMax:
+		v, err := rule.ValueFloat()
+		if err != nil {
+			return nil
+		}
+		return v
+	case ValTypAllValuesExcept, ValTypOnlyTheseValues:
+		parts := strings.Split(rule.Value.(string), ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return parts
+	case ValTypEnumeration:
+		if x, ok := FindSimple(rule.Value.(string)); ok {
+			return x.Values()
+		}
+		return nil
+	case ValTypRequiredIf:
+		return rule.Value.(requiredIf).Condition
+	}
+	return rule.Value
+}
+
+func writeRuleSet() {
+	if *rulesOutFile == "" {
+		return
+	}
+	data, err := json.MarshalIndent(RuleSet, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile(*rulesOutFile, data, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
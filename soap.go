@@ -0,0 +1,165 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+)
+
+// soapClient returns the parts of the SOAP client generated from the WSDL:
+// SOAPHeader with the header blocks of the bindings, a New<Port>Client for
+// every SOAP port, a SOAPClient method for every operation and the types of
+// the declared fault details. The types used are generated too, so it runs
+// before Types is written.
+func soapClient() string {
+	if fileType != extWSDL {
+		log.Fatal("-soap needs a WSDL input file")
+	}
+	d := &wsdlSc
+	b := NewBuffer()
+
+	header := NewBuffer()
+	headers := map[string]bool{}
+	operations := map[string]operation{}
+	var names []string
+
+	for _, bd := range d.Binding {
+		if bd.Version() == 0 {
+			continue
+		}
+		pt, ok := d.FindPortType(bd.Type)
+		if !ok {
+			log.Fatalf("could not find portType %s of binding %s", bd.Type, bd.Name)
+		}
+		for _, bo := range bd.Operation {
+			for _, h := range bo.Input.Header {
+				e, ok := soapPart(d, h.Message, h.Part)
+				if !ok || headers[e.Name] {
+					continue
+				}
+				headers[e.Name] = true
+				if r := e.GetRelated(); r != nil {
+					r.Generate()
+				}
+				header.Sprintf("\t%s %s `xml:\"%s %s,omitempty\" json:\"%s,omitempty\"`\r\n", UpperFirstLetter(e.Name), e.TransformType(), getSchema().TargetNamespace, e.Name, ToSnake(e.Name))
+			}
+			for _, op := range pt.Operation {
+				if op.Name != bo.Name {
+					continue
+				}
+				if _, ok := operations[op.Name]; !ok {
+					names = append(names, op.Name)
+				}
+				operations[op.Name] = op
+			}
+		}
+	}
+
+	b.Sprintf("// SOAPHeader holds the SOAP header blocks of the operations.\r\n")
+	b.Sprintf("type SOAPHeader struct {\r\n%s}\r\n\r\n", header.String())
+
+	for _, p := range d.Service.Port {
+		bd, ok := d.FindBinding(p.Binding)
+		if !ok || bd.Version() == 0 {
+			continue
+		}
+		b.Sprintf(`// New%[1]sClient returns a client of the %[2]s port.
+			func New%[1]sClient() *SOAPClient {
+				return &SOAPClient{
+					URL:     %[3]q,
+					Version: SOAP%[4]d,
+					Actions: map[string]string{
+			`, UpperFirstLetter(p.Name), p.Name, p.Address.Location, bd.Version())
+		for _, bo := range bd.Operation {
+			b.Sprintf("%q: %q,\r\n", bo.Name, bo.SOAPAction())
+		}
+		b.Sprintf("},\r\n}\r\n}\r\n\r\n")
+	}
+
+	sort.Strings(names)
+	faults := map[string]string{}
+	for _, name := range names {
+		op := operations[name]
+		request, ok1 := soapMessageType(d, op.Input.Message)
+		response, ok2 := soapMessageType(d, op.Output.Message)
+		if !ok1 || !ok2 {
+			log.Printf("Operation %s: request or response type not generated, skipping", name)
+			continue
+		}
+		if desc := strings.Join(strings.Fields(op.Documentation.Contents), " "); desc != "" {
+			b.Sprintf("// %s calls the %s operation. %s\r\n", UpperFirstLetter(name), name, desc)
+		} else {
+			b.Sprintf("// %s calls the %s operation.\r\n", UpperFirstLetter(name), name)
+		}
+		b.Sprintf(`func (c *SOAPClient) %[1]s(ctx context.Context, request *%[2]s) (*%[3]s, error) {
+				response := &%[3]s{}
+				if err := c.call(ctx, %[4]q, request, response); err != nil {
+					return nil, err
+				}
+				return response, nil
+			}
+
+			`, UpperFirstLetter(name), request, response, name)
+
+		for _, f := range op.Fault {
+			e, ok := soapPart(d, f.Message, "")
+			if !ok {
+				continue
+			}
+			r := e.GetRelated()
+			if r == nil || !e.GetType().IsComplexType() {
+				continue
+			}
+			r.Generate()
+			faults[strings.TrimSpace(getSchema().TargetNamespace+" "+e.Name)] = e.GetType().String()
+		}
+	}
+
+	var keys []string
+	for k := range faults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b.Sprintf("// soapFaultDetails creates the types of the fault details declared by the\r\n// operations, keyed by namespace and element name.\r\n")
+	b.Sprintf("var soapFaultDetails = map[string]func() interface{}{\r\n")
+	for _, k := range keys {
+		b.Sprintf("%q: func() interface{} { return &%s{} },\r\n", k, faults[k])
+	}
+	b.Sprintf("}\r\n")
+
+	return b.String() + templateSOAP
+}
+
+// soapPart returns the schema element of a message part.
+func soapPart(d *definitions, name Type, part string) (element, bool) {
+	m, ok := d.FindMessage(name)
+	if !ok {
+		log.Printf("Could not find message %s, skipping", name)
+		return element{}, false
+	}
+	p, ok := m.FindPart(part)
+	if !ok || p.Element == "" {
+		log.Printf("Message %s has no element part %s, skipping", name, part)
+		return element{}, false
+	}
+	for _, e := range getSchema().Element {
+		// Requests and responses of LowerCamel APIs are renamed.
+		if e.Name == p.Element.String() || (profile.LowerCamel && e.Name == UpperFirstLetter(p.Element.String())) {
+			return e, true
+		}
+	}
+	log.Printf("Could not find element %s of message %s, skipping", p.Element, name)
+	return element{}, false
+}
+
+// soapMessageType returns the generated type of the element a message sends
+// in the SOAP body.
+func soapMessageType(d *definitions, name Type) (string, bool) {
+	e, ok := soapPart(d, name, "")
+	if !ok {
+		return "", false
+	}
+	t := e.GetType().String()
+	_, ok = Types[t]
+	return t, ok
+}
@@ -0,0 +1,239 @@
+package main
+
+// SOAP client runtime. Emitted with -soap.
+var templateSOAP = `
+// SOAPVersion selects the envelope namespace and HTTP headers of a SOAPClient.
+type SOAPVersion byte
+
+const (
+	SOAP11 SOAPVersion = iota + 1
+	SOAP12
+)
+
+const (
+	soap11Envelope = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Envelope = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+func (v SOAPVersion) namespace() string {
+	if v == SOAP12 {
+		return soap12Envelope
+	}
+	return soap11Envelope
+}
+
+// SOAPClient calls the operations of the WSDL over SOAP. Create it with one of
+// the New<Port>Client functions, which set URL, Version and Actions from the
+// service port.
+type SOAPClient struct {
+	URL     string
+	Version SOAPVersion
+
+	// Actions maps operation names to their SOAPAction.
+	Actions map[string]string
+
+	// Header holds the header blocks sent with every call.
+	Header *SOAPHeader
+
+	// HTTPClient sends the calls. Default: the package HTTPClient
+	HTTPClient *http.Client
+
+	// Prepare, when set, is called with every request before it is sent, e.g.
+	// to add the query parameters eBay's SOAP gateway routes calls by.
+	Prepare func(operation string, request *http.Request)
+}
+
+// SOAPEnvelope is the document sent and received by a SOAPClient.
+type SOAPEnvelope struct {
+	XMLName xml.Name
+	Header  *SOAPHeader
+	Body    SOAPBody
+}
+
+// SOAPBody holds the request or response element of an operation, or the
+// Fault answered instead of the response.
+type SOAPBody struct {
+	Content interface{}
+	Fault   *SOAPFault
+}
+
+func (b SOAPBody) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if b.Content != nil {
+		if err := e.Encode(b.Content); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func (b *SOAPBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "Fault" && (t.Name.Space == soap11Envelope || t.Name.Space == soap12Envelope):
+				b.Fault = &SOAPFault{}
+				err = d.DecodeElement(b.Fault, &t)
+			case b.Content != nil:
+				err = d.DecodeElement(b.Content, &t)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// SOAPFault is the error returned for a SOAP Fault. SOAP 1.2 faults are
+// mapped to the SOAP 1.1 fields: Code/Value to Code, Reason/Text to String
+// and Role to Actor.
+type SOAPFault struct {
+	Code    string
+	Subcode string
+	String  string
+	Actor   string
+
+	// Detail holds the fault detail decoded into the type the WSDL declares
+	// for it, nil for undeclared details. RawDetail holds the detail XML.
+	Detail    interface{}
+	RawDetail string
+}
+
+func (f *SOAPFault) Error() string {
+	if f.Subcode != "" {
+		return fmt.Sprintf("soap fault %s (%s): %s", f.Code, f.Subcode, f.String)
+	}
+	return fmt.Sprintf("soap fault %s: %s", f.Code, f.String)
+}
+
+func (f *SOAPFault) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var fault struct {
+		FaultCode   string ` + "`xml:\"faultcode\"`" + `
+		FaultString string ` + "`xml:\"faultstring\"`" + `
+		FaultActor  string ` + "`xml:\"faultactor\"`" + `
+		Code        string ` + "`xml:\"Code>Value\"`" + `
+		Subcode     string ` + "`xml:\"Code>Subcode>Value\"`" + `
+		Reason      string ` + "`xml:\"Reason>Text\"`" + `
+		Role        string ` + "`xml:\"Role\"`" + `
+		Detail      struct {
+			XML string ` + "`xml:\",innerxml\"`" + `
+		} ` + "`xml:\"detail\"`" + `
+		Detail12 struct {
+			XML string ` + "`xml:\",innerxml\"`" + `
+		} ` + "`xml:\"Detail\"`" + `
+	}
+	if err := d.DecodeElement(&fault, &start); err != nil {
+		return err
+	}
+	*f = SOAPFault{
+		Code:      fault.FaultCode + fault.Code,
+		Subcode:   fault.Subcode,
+		String:    fault.FaultString + fault.Reason,
+		Actor:     fault.FaultActor + fault.Role,
+		RawDetail: strings.TrimSpace(fault.Detail.XML + fault.Detail12.XML),
+	}
+	f.Detail = soapFaultDetail(f.RawDetail)
+	return nil
+}
+
+// soapFaultDetail decodes the first element of a fault detail into the type
+// soapFaultDetails holds for it.
+func soapFaultDetail(detail string) interface{} {
+	d := xml.NewDecoder(strings.NewReader(detail))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		newDetail, ok := soapFaultDetails[start.Name.Space+" "+start.Name.Local]
+		if !ok {
+			return nil
+		}
+		v := newDetail()
+		if err := d.DecodeElement(v, &start); err != nil {
+			return nil
+		}
+		return v
+	}
+}
+
+// call sends request as the body of operation and decodes the body of the
+// answer into response.
+func (c *SOAPClient) call(ctx context.Context, operation string, request, response interface{}) error {
+	if v, ok := request.(interface{ Validate() error }); ok && RequestValidation {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
+	body := bytes.NewBufferString(xml.Header)
+	envelope := SOAPEnvelope{
+		XMLName: xml.Name{Space: c.Version.namespace(), Local: "Envelope"},
+		Header:  c.Header,
+		Body:    SOAPBody{Content: request},
+	}
+	if err := xml.NewEncoder(body).Encode(envelope); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.URL, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	action := c.Actions[operation]
+	if c.Version == SOAP12 {
+		req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8; action="+strconv.Quote(action))
+	} else {
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		req.Header.Set("SOAPAction", strconv.Quote(action))
+	}
+	if c.Prepare != nil {
+		c.Prepare(operation, req)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = HTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	answer := SOAPEnvelope{Body: SOAPBody{Content: response}}
+	if err := xml.Unmarshal(data, &answer); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("soap: %s", resp.Status)
+		}
+		return err
+	}
+	if answer.Body.Fault != nil {
+		return answer.Body.Fault
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("soap: %s", resp.Status)
+	}
+	return nil
+}
+`
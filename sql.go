@@ -0,0 +1,466 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// sqlTypes maps the proto scalar types of newProtoField to Postgres types.
+var sqlTypes = map[string]string{
+	"string": "TEXT",
+	"int64":  "BIGINT",
+	"int32":  "INTEGER",
+	"double": "DOUBLE PRECISION",
+	"float":  "REAL",
+	"bool":   "BOOLEAN",
+	"bytes":  "BYTEA",
+}
+
+// sqlNulls maps proto scalar types to the database/sql null type scanning
+// them and its value field.
+var sqlNulls = map[string][2]string{
+	"string": {"sql.NullString", "String"},
+	"int64":  {"sql.NullInt64", "Int64"},
+	"int32":  {"sql.NullInt64", "Int64"},
+	"double": {"sql.NullFloat64", "Float64"},
+	"float":  {"sql.NullFloat64", "Float64"},
+	"bool":   {"sql.NullBool", "Bool"},
+}
+
+// sqlPointer is a pointer to a struct whose fields are flattened into the
+// columns of a table.
+type sqlPointer struct {
+	Path string // Go path below the row
+	Type string
+}
+
+type sqlColumn struct {
+	Name    string
+	Field   protoField
+	Path    string       // Go path below the row, "" for a repeated scalar
+	Parents []sqlPointer // pointers on Path
+	Check   string
+}
+
+// sqlTable holds the rows of a root response type, or the elements of a
+// repeated field of its parent table.
+type sqlTable struct {
+	Name     string
+	GoName   string
+	RowType  string
+	Parent   *sqlTable
+	Path     string       // Go path of the repeated field in the parent row
+	Parents  []sqlPointer // pointers on Path
+	Columns  []sqlColumn
+	Pointers []sqlPointer // flattened pointers of the row, outermost first
+	Children []*sqlTable
+}
+
+// sqlSchema maps response types to tables: fields of nested types are
+// flattened into columns prefixed with the field name, repeated fields become
+// child tables referencing their parent row. Types nested deeper than depth
+// are left out.
+type sqlSchema struct {
+	depth  int
+	tables []*sqlTable
+	names  map[string]bool
+}
+
+func newSQLSchema(depth int) *sqlSchema {
+	return &sqlSchema{depth: depth, names: map[string]bool{}}
+}
+
+// sqlIdentifier shortens names longer than Postgres allows (63 bytes),
+// keeping them unique with a hash.
+func sqlIdentifier(name string) string {
+	if len(name) <= 63 {
+		return name
+	}
+	return fmt.Sprintf("%s_%08x", name[:54], hash(name))
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	name = sqlIdentifier(name)
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = sqlIdentifier(fmt.Sprintf("%s_%d", name, i))
+	}
+	used[unique] = true
+	return unique
+}
+
+// Root adds the tables of a root response type.
+func (s *sqlSchema) Root(c *complexType) {
+	name := strings.TrimSuffix(c.GetName(), "Type")
+	t := s.table(ToSnake(name), name, c.GetName(), nil)
+	s.flatten(t, c, "", "", nil, 0, map[string]bool{"id": true})
+}
+
+func (s *sqlSchema) table(name, goName, rowType string, parent *sqlTable) *sqlTable {
+	t := &sqlTable{Name: uniqueName(name, s.names), GoName: goName, RowType: rowType, Parent: parent}
+	s.tables = append(s.tables, t)
+	if parent != nil {
+		parent.Children = append(parent.Children, t)
+	}
+	return t
+}
+
+func (s *sqlSchema) flatten(t *sqlTable, c *complexType, prefix, path string, parents []sqlPointer, depth int, columns map[string]bool) {
+	fields, _ := protoFields(c, "SQL")
+	for _, f := range fields {
+		name := f.Name
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		goPath := joinPath(path, f.GoName)
+
+		if f.Kind == protoMessage && depth+1 > s.depth {
+			log.Printf("SQL: %s.%s is nested deeper than %d, skipping", t.Name, name, s.depth)
+			continue
+		}
+		if f.Repeated {
+			child := s.table(t.Name+"_"+name, t.GoName+UpperFirstLetter(GoCamelCase(name)), f.GoType, t)
+			child.Path, child.Parents = goPath, parents
+			childColumns := map[string]bool{"id": true, "parent_id": true, "position": true}
+			if f.Kind == protoMessage {
+				sub, _ := FindComplex(f.GoType)
+				s.flatten(child, sub, "", "", nil, depth+1, childColumns)
+			} else {
+				f.Repeated = false
+				child.Columns = append(child.Columns, newSQLColumn(uniqueName("value", childColumns), f, "", nil))
+			}
+			continue
+		}
+		if f.Kind == protoMessage {
+			sub, _ := FindComplex(f.GoType)
+			inner := parents
+			if f.Pointer {
+				p := sqlPointer{Path: goPath, Type: f.GoType}
+				t.Pointers = append(t.Pointers, p)
+				inner = append(append([]sqlPointer{}, parents...), p)
+			}
+			s.flatten(t, sub, name, goPath, inner, depth+1, columns)
+			continue
+		}
+		t.Columns = append(t.Columns, newSQLColumn(uniqueName(name, columns), f, goPath, parents))
+	}
+}
+
+func newSQLColumn(name string, f protoField, path string, parents []sqlPointer) sqlColumn {
+	column := sqlColumn{Name: name, Field: f, Path: path, Parents: parents}
+	if f.Kind == protoEnum {
+		if x, ok := FindSimple(f.GoType); ok {
+			var values []string
+			for _, v := range x.Values() {
+				values = append(values, "'"+strings.Replace(v, "'", "''", -1)+"'")
+			}
+			column.Check = fmt.Sprintf("%s IN (%s)", sqlQuote(name), strings.Join(values, ", "))
+		}
+	}
+	return column
+}
+
+func sqlQuote(name string) string {
+	return `"` + name + `"`
+}
+
+// scalar returns the proto scalar type of the column's values, string for
+// CodeTypes.
+func (c sqlColumn) scalar() string {
+	if c.Field.Kind == protoEnum {
+		return "string"
+	}
+	return c.Field.ProtoType
+}
+
+// DDL returns the CREATE TABLE statement of the table.
+func (t *sqlTable) DDL() string {
+	lines := []string{`  "id" BIGSERIAL PRIMARY KEY`}
+	if t.Parent != nil {
+		lines = append(lines,
+			fmt.Sprintf(`  "parent_id" BIGINT NOT NULL REFERENCES %s ("id") ON DELETE CASCADE`, sqlQuote(t.Parent.Name)),
+			`  "position" INTEGER NOT NULL`)
+	}
+	for _, c := range t.Columns {
+		line := fmt.Sprintf("  %s %s", sqlQuote(c.Name), sqlTypes[c.scalar()])
+		if c.Check != "" {
+			line += " CHECK (" + c.Check + ")"
+		}
+		lines = append(lines, line)
+	}
+	ddl := fmt.Sprintf("CREATE TABLE %s (\n%s\n);\n", sqlQuote(t.Name), strings.Join(lines, ",\n"))
+	if t.Parent != nil {
+		ddl += fmt.Sprintf("\nCREATE INDEX %s ON %s (\"parent_id\");\n", sqlQuote(sqlIdentifier(t.Name+"_parent_id")), sqlQuote(t.Name))
+	}
+	return ddl
+}
+
+// ref returns the Go expression of path below the row x.
+func ref(path string) string {
+	if path == "" {
+		return "(*x)"
+	}
+	return "x." + path
+}
+
+// nilGuard returns the condition telling none of the pointers is nil.
+func nilGuard(pointers []sqlPointer) string {
+	var conditions []string
+	for _, p := range pointers {
+		conditions = append(conditions, ref(p.Path)+" != nil")
+	}
+	return strings.Join(conditions, " && ")
+}
+
+// InsertArg returns the statement setting args[i] to the column's value.
+// Unset values are stored as NULL.
+func (c sqlColumn) InsertArg(i int) string {
+	v := ref(c.Path)
+	var set string
+	switch {
+	case c.Field.Kind == protoNull:
+		set = fmt.Sprintf("args[%d] = %s.%s\r\n", i, v, c.Field.GoType)
+	case c.Field.Pointer:
+		set = fmt.Sprintf("if %s != nil {\r\nargs[%d] = %s(*%[1]s)\r\n}\r\n", v, i, protoGoTypes[c.scalar()])
+	case c.scalar() == "string":
+		set = fmt.Sprintf("args[%d] = sqlText(string(%s))\r\n", i, v)
+	default:
+		set = fmt.Sprintf("args[%d] = %s(%s)\r\n", i, protoGoTypes[c.scalar()], v)
+	}
+	if len(c.Parents) > 0 {
+		return fmt.Sprintf("if %s {\r\n%s}\r\n", nilGuard(c.Parents), set)
+	}
+	return set
+}
+
+// Scan returns the declarations before rows.Scan, the scan target and the
+// statements after it, which copy values scanned into database/sql nulls.
+func (c sqlColumn) Scan(i int) (declare, target, assign string) {
+	v := ref(c.Path)
+	if c.Field.Kind == protoNull || c.scalar() == "bytes" {
+		return "", "&" + v, ""
+	}
+	null := sqlNulls[c.scalar()]
+	tmp := fmt.Sprintf("c%d", i)
+	declare = fmt.Sprintf("var %s %s\r\n", tmp, null[0])
+	if c.Field.Pointer {
+		assign = fmt.Sprintf("if %[1]s.Valid {\r\nv := %[3]s(%[1]s.%[4]s)\r\n%[2]s = &v\r\n}\r\n", tmp, v, c.Field.GoType, null[1])
+	} else {
+		assign = fmt.Sprintf("if %[1]s.Valid {\r\n%[2]s = %[3]s(%[1]s.%[4]s)\r\n}\r\n", tmp, v, c.Field.GoType, null[1])
+	}
+	return declare, "&" + tmp, assign
+}
+
+// IsSet returns the condition telling the column held a value, once scanned.
+func (c sqlColumn) IsSet() string {
+	v := ref(c.Path)
+	switch {
+	case c.Field.Kind == protoNull:
+		return v + ".Valid"
+	case c.Field.Pointer, c.scalar() == "bytes":
+		return v + " != nil"
+	case c.scalar() == "string":
+		return v + ` != ""`
+	case c.scalar() == "bool":
+		return v
+	}
+	return v + " != 0"
+}
+
+// Insert returns the function inserting a row of the table and its children.
+func (t *sqlTable) Insert() string {
+	b := NewBuffer()
+	var names, params []string
+	var args []string
+	if t.Parent != nil {
+		names = append(names, `"parent_id"`, `"position"`)
+		args = append(args, "parentID", "position")
+	}
+	for _, c := range t.Columns {
+		names = append(names, sqlQuote(c.Name))
+		args = append(args, "nil")
+	}
+	for i := range names {
+		params = append(params, fmt.Sprintf("$%d", i+1))
+	}
+	query := fmt.Sprintf(`INSERT INTO %s DEFAULT VALUES RETURNING "id"`, sqlQuote(t.Name))
+	if len(names) > 0 {
+		query = fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) RETURNING "id"`, sqlQuote(t.Name), strings.Join(names, ", "), strings.Join(params, ", "))
+	}
+
+	if t.Parent == nil {
+		b.Sprintf("\r\n// Insert%s inserts x into %s and its repeated fields into the child tables,\r\n", t.GoName, t.Name)
+		b.Sprintf("// returning the id of the %s row. Pass a *sql.Tx to insert all rows or none.\r\n", t.Name)
+		b.Sprintf("func Insert%s(ctx context.Context, db SQLDB, x *%s) (id int64, err error) {\r\n", t.GoName, t.RowType)
+	} else {
+		b.Sprintf("\r\nfunc insert%s(ctx context.Context, db SQLDB, parentID int64, position int, x *%s) (id int64, err error) {\r\n", t.GoName, t.RowType)
+	}
+	b.Sprintf("args := []interface{}{%s}\r\n", strings.Join(args, ", "))
+	offset := len(args) - len(t.Columns)
+	for i, c := range t.Columns {
+		b.Sprintf("%s", c.InsertArg(offset+i))
+	}
+	b.Sprintf("if err = db.QueryRowContext(ctx, `%s`, args...).Scan(&id); err != nil {\r\nreturn 0, err\r\n}\r\n", query)
+	for _, child := range t.Children {
+		loop := fmt.Sprintf("for i := range %[1]s {\r\nif _, err = insert%[2]s(ctx, db, id, i, &%[1]s[i]); err != nil {\r\nreturn 0, err\r\n}\r\n}\r\n", ref(child.Path), child.GoName)
+		if len(child.Parents) > 0 {
+			loop = fmt.Sprintf("if %s {\r\n%s}\r\n", nilGuard(child.Parents), loop)
+		}
+		b.Sprintf("%s", loop)
+	}
+	b.Sprintf("return id, nil\r\n}\r\n")
+	return b.String()
+}
+
+// Scan returns the function reading rows of the table, with their children.
+// Pointers to flattened types are allocated before scanning and set back to
+// nil when none of their columns or child rows held a value.
+func (t *sqlTable) Scan() string {
+	b := NewBuffer()
+	names := []string{`"id"`}
+	targets := []string{"&id"}
+	var declare, assign []string
+	for i, c := range t.Columns {
+		names = append(names, sqlQuote(c.Name))
+		d, target, a := c.Scan(i)
+		declare = append(declare, d)
+		targets = append(targets, target)
+		assign = append(assign, a)
+	}
+	var allocate string
+	for _, p := range t.Pointers {
+		allocate += fmt.Sprintf("%s = &%s{}\r\n", ref(p.Path), p.Type)
+	}
+	scan := func(source string) string {
+		return fmt.Sprintf("%s%sif err = %s.Scan(%s); err != nil {\r\nreturn nil, err\r\n}\r\n%s", allocate, strings.Join(declare, ""), source, strings.Join(targets, ", "), strings.Join(assign, ""))
+	}
+
+	if t.Parent == nil {
+		query := fmt.Sprintf(`SELECT %s FROM %s WHERE "id" = $1`, strings.Join(names, ", "), sqlQuote(t.Name))
+		b.Sprintf("\r\n// Scan%s reads the %s row id, with the rows of its child tables.\r\n", t.GoName, t.Name)
+		b.Sprintf("func Scan%s(ctx context.Context, db SQLDB, id int64) (x *%s, err error) {\r\n", t.GoName, t.RowType)
+		b.Sprintf("x = &%s{}\r\nrow := db.QueryRowContext(ctx, `%s`, id)\r\n", t.RowType, query)
+		b.Sprintf("%s", scan("row"))
+		b.Sprintf("if err = scan%sChildren(ctx, db, id, x); err != nil {\r\nreturn nil, err\r\n}\r\nreturn x, nil\r\n}\r\n", t.GoName)
+	} else {
+		query := fmt.Sprintf(`SELECT %s FROM %s WHERE "parent_id" = $1 ORDER BY "position"`, strings.Join(names, ", "), sqlQuote(t.Name))
+		b.Sprintf("\r\nfunc scan%s(ctx context.Context, db SQLDB, parentID int64) (list []%s, err error) {\r\n", t.GoName, t.RowType)
+		b.Sprintf("rows, err := db.QueryContext(ctx, `%s`, parentID)\r\nif err != nil {\r\nreturn nil, err\r\n}\r\ndefer rows.Close()\r\n", query)
+		b.Sprintf("var ids []int64\r\nfor rows.Next() {\r\nvar id int64\r\nx := new(%s)\r\n", t.RowType)
+		b.Sprintf("%s", scan("rows"))
+		b.Sprintf("ids = append(ids, id)\r\nlist = append(list, *x)\r\n}\r\nif err = rows.Err(); err != nil {\r\nreturn nil, err\r\n}\r\nrows.Close()\r\n")
+		b.Sprintf("for i := range list {\r\nif err = scan%sChildren(ctx, db, ids[i], &list[i]); err != nil {\r\nreturn nil, err\r\n}\r\n}\r\nreturn list, nil\r\n}\r\n", t.GoName)
+	}
+
+	b.Sprintf("\r\nfunc scan%sChildren(ctx context.Context, db SQLDB, id int64, x *%s) (err error) {\r\n", t.GoName, t.RowType)
+	for _, child := range t.Children {
+		b.Sprintf("if %s, err = scan%s(ctx, db, id); err != nil {\r\nreturn err\r\n}\r\n", ref(child.Path), child.GoName)
+	}
+	// Whether a pointer held anything is decided before any is set to nil,
+	// innermost first.
+	for i, p := range t.Pointers {
+		set := []string{"false"}
+		for _, c := range t.Columns {
+			if sqlUnder(c.Parents, p) {
+				set = append(set, c.IsSet())
+			}
+		}
+		for _, child := range t.Children {
+			if sqlUnder(child.Parents, p) {
+				set = append(set, "len("+ref(child.Path)+") > 0")
+			}
+		}
+		if len(set) > 1 {
+			set = set[1:]
+		}
+		b.Sprintf("set%d := %s\r\n", i, strings.Join(set, " || "))
+	}
+	for i := len(t.Pointers) - 1; i >= 0; i-- {
+		b.Sprintf("if !set%d {\r\n%s = nil\r\n}\r\n", i, ref(t.Pointers[i].Path))
+	}
+	b.Sprintf("return nil\r\n}\r\n")
+	return b.String()
+}
+
+func sqlUnder(parents []sqlPointer, p sqlPointer) bool {
+	for _, parent := range parents {
+		if parent == p {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlRoots returns the root response types of -sql-roots, by default the
+// response types of every exported call.
+func sqlRoots() (roots []*complexType) {
+	names := strings.Split(strings.Replace(*sqlRootTypes, " ", "", -1), ",")
+	if *sqlRootTypes == "" {
+		names = nil
+		for _, call := range exportedElements {
+			names = append(names, call+"ResponseType")
+		}
+	}
+	for _, name := range names {
+		if _, ok := Types[name]; !ok {
+			log.Fatalf("-sql-roots: %s is not a generated type", name)
+		}
+		c, _ := FindComplex(name)
+		roots = append(roots, c)
+	}
+	return
+}
+
+// writeSQL writes the Postgres tables of the -sql-roots types to -sql and the
+// functions inserting and reading them to -sql-go.
+func writeSQL() {
+	if *sqlFile == "" && *sqlGoFile == "" {
+		return
+	}
+	s := newSQLSchema(*sqlDepth)
+	for _, c := range sqlRoots() {
+		s.Root(c)
+	}
+
+	if *sqlFile != "" {
+		b := NewBuffer()
+		b.Sprintf("-- Code generated by xsdbay. DO NOT EDIT.\n-- eBay API version %s\n", *apiVersion)
+		for _, t := range s.tables {
+			b.Sprintf("\n%s", t.DDL())
+		}
+		if err := ioutil.WriteFile(*sqlFile, b.Bytes(), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *sqlGoFile != "" {
+		b := NewBuffer()
+		b.Sprintf("// Code generated by xsdbay. DO NOT EDIT.\r\n\r\npackage ebaysvc\r\n\r\nimport (\r\n\"context\"\r\n\"database/sql\"\r\n)\r\n")
+		b.Sprintf("%s", templateSQL)
+		for _, t := range s.tables {
+			b.Sprintf("%s", t.Insert())
+			b.Sprintf("%s", t.Scan())
+		}
+		if err := ioutil.WriteFile(*sqlGoFile, formatCode(b.Bytes()), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+var templateSQL = `
+// SQLDB is implemented by *sql.DB and *sql.Tx.
+type SQLDB interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlText stores empty strings as NULL.
+func sqlText(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+`
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sqlTestSchema = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ns="urn:ebay:apis:eBLBaseComponents" targetNamespace="urn:ebay:apis:eBLBaseComponents" elementFormDefault="qualified">
+<xs:element name="GetItemResponse" type="ns:GetItemResponseType"/>
+<xs:complexType name="GetItemResponseType"><xs:sequence>
+ <xs:element name="ItemID" type="xs:string" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="Item" type="ns:ItemType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="Tag" type="xs:string" minOccurs="0" maxOccurs="unbounded"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="ItemType"><xs:sequence>
+ <xs:element name="Title" type="xs:string" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="Currency" type="ns:CurrencyCodeType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="Seller" type="ns:UserType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="ShippingOption" type="ns:ShippingType" minOccurs="0" maxOccurs="unbounded"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="UserType"><xs:sequence>
+ <xs:element name="UserID" type="xs:string" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="ShippingType"><xs:sequence>
+ <xs:element name="Service" type="xs:string" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><Returned>Always</Returned></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:simpleType name="CurrencyCodeType"><xs:restriction base="xs:token">
+ <xs:enumeration value="USD"/><xs:enumeration value="EUR"/>
+</xs:restriction></xs:simpleType>
+</xs:schema>`
+
+func Test_sqlSchema_flatten(t *testing.T) {
+	loadSchema(t, sqlTestSchema, "GetItem")
+	root, _ := FindComplex("GetItemResponseType")
+
+	type table struct {
+		name, parent, path string
+		columns            []string
+		pointers           []string
+	}
+	tests := []struct {
+		name  string
+		depth int
+		want  []table
+	}{
+		{"nested", 3, []table{
+			{"get_item_response", "", "", []string{"item_id", "item_title", "item_currency", "item_seller_user_id"}, []string{"Item", "Item.Seller"}},
+			{"get_item_response_item_shipping_option", "get_item_response", "Item.ShippingOption", []string{"service"}, nil},
+			{"get_item_response_tag", "get_item_response", "Tag", []string{"value"}, nil},
+		}},
+		// Types nested deeper than depth are left out.
+		{"depth 1", 1, []table{
+			{"get_item_response", "", "", []string{"item_id", "item_title", "item_currency"}, []string{"Item"}},
+			{"get_item_response_tag", "get_item_response", "Tag", []string{"value"}, nil},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSQLSchema(tt.depth)
+			s.Root(root)
+
+			var got []table
+			for _, x := range s.tables {
+				tb := table{name: x.Name, path: x.Path}
+				if x.Parent != nil {
+					tb.parent = x.Parent.Name
+				}
+				for _, c := range x.Columns {
+					tb.columns = append(tb.columns, c.Name)
+				}
+				for _, p := range x.Pointers {
+					tb.pointers = append(tb.pointers, p.Path)
+				}
+				got = append(got, tb)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tables = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+
+	s := newSQLSchema(3)
+	s.Root(root)
+	currency := s.tables[0].Columns[2]
+	if want := `"item_currency" IN ('USD', 'EUR')`; currency.Check != want {
+		t.Errorf("item_currency check = %s, want %s", currency.Check, want)
+	}
+	if len(currency.Parents) != 1 || currency.Parents[0].Path != "Item" {
+		t.Errorf("item_currency parents = %+v, want Item", currency.Parents)
+	}
+}
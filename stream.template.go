@@ -0,0 +1,85 @@
+package main
+
+// Streaming response decoder. Emitted when a response contains repeated
+// elements inside an *Array wrapper.
+var templateStream = `
+// decodeStream walks a response document token by token. Every element found
+// at path (below the root element) is handed to fn, which must consume it with
+// DecodeElement. All other content is buffered and decoded into envelope at the
+// end, so memory use does not grow with the number of streamed elements.
+func decodeStream(r io.Reader, path []string, envelope interface{}, fn func(*xml.Decoder, *xml.StartElement) error) error {
+	dec := xml.NewDecoder(r)
+	rest := bytes.NewBuffer(nil)
+	enc := xml.NewEncoder(rest)
+
+	var stack []string
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				stack = append(stack, t.Name.Local)
+				if streamPathMatch(stack, path) {
+					if err = fn(dec, &t); err != nil {
+						return err
+					}
+					stack = stack[:len(stack)-1]
+					continue
+				}
+			}
+			depth++
+			tok = streamStripNamespace(t)
+		case xml.EndElement:
+			depth--
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			t.Name.Space = ""
+			tok = t
+		case xml.ProcInst, xml.Comment, xml.Directive:
+			continue
+		}
+		if err = enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	return xml.Unmarshal(rest.Bytes(), envelope)
+}
+
+func streamPathMatch(stack, path []string) bool {
+	if len(stack) != len(path) {
+		return false
+	}
+	for i := range path {
+		if stack[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func streamStripNamespace(start xml.StartElement) xml.StartElement {
+	start.Name.Space = ""
+	attrs := start.Attr[:0:0]
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		attr.Name.Space = ""
+		attrs = append(attrs, attr)
+	}
+	start.Attr = attrs
+	return start
+}
+`
@@ -0,0 +1,80 @@
+package ebaysvc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthNAuthFlow(t *testing.T) {
+	expiry := time.Now().Add(10 * 24 * time.Hour).UTC().Truncate(time.Second)
+	status := "Active"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-EBAY-API-DEV-NAME") != "dev" || r.Header.Get("X-EBAY-API-CERT-NAME") != "cert" {
+			t.Errorf("application keys not sent: %v", r.Header)
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		body := string(data)
+		switch r.Header.Get("X-EBAY-API-CALL-NAME") {
+		case "GetSessionID":
+			if !strings.Contains(body, "<RuName>my-ru</RuName>") || strings.Contains(body, "RequesterCredentials") {
+				t.Errorf("GetSessionID body %s", body)
+			}
+			w.Write([]byte(`<GetSessionIDResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Ack>Success</Ack><SessionID>S 1</SessionID></GetSessionIDResponse>`))
+		case "FetchToken":
+			if !strings.Contains(body, "<SessionID>S 1</SessionID>") {
+				t.Errorf("FetchToken body %s", body)
+			}
+			w.Write([]byte(`<FetchTokenResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Ack>Success</Ack><eBayAuthToken>token</eBayAuthToken>` +
+				`<HardExpirationTime>2030-01-01T00:00:00.000Z</HardExpirationTime></FetchTokenResponse>`))
+		case "GetTokenStatus":
+			if !strings.Contains(body, "<eBayAuthToken>token</eBayAuthToken>") {
+				t.Errorf("GetTokenStatus body %s", body)
+			}
+			w.Write([]byte(`<GetTokenStatusResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Ack>Success</Ack><TokenStatus><Status>` + status +
+				`</Status><ExpirationTime>` + expiry.Format(time.RFC3339) + `</ExpirationTime></TokenStatus></GetTokenStatusResponse>`))
+		}
+	}))
+	defer srv.Close()
+	APIGateway, APIDevName, APIAppName, APICertName = srv.URL, "dev", "app", "cert"
+
+	var warned string
+	store := &MemoryTokenStore{}
+	flow := &AuthNAuthFlow{RuName: "my-ru", SiteID: "0", Store: store, Warn: func(user string, token *AuthToken) { warned = user }}
+	ctx := context.Background()
+	if _, err := flow.Credentials(ctx, "bob"); err != ErrTokenNotFound {
+		t.Fatalf("Credentials before Finish: %v", err)
+	}
+
+	id, signIn, err := flow.Start(ctx)
+	if err != nil || id != "S 1" || signIn != SignInURL+"?SignIn&SessID=S+1&runame=my-ru" {
+		t.Fatalf("Start: %q %q %v", id, signIn, err)
+	}
+	token, err := flow.Finish(ctx, "bob", id)
+	if err != nil || token.Token != "token" || token.Expiry.Year() != 2030 {
+		t.Fatalf("Finish: %+v %v", token, err)
+	}
+	credentials, err := flow.Credentials(ctx, "bob")
+	if err != nil || credentials != AuthNAuth("token") {
+		t.Fatalf("Credentials: %v %v", credentials, err)
+	}
+
+	// The token expires within the warning period.
+	token, err = flow.Check(ctx, "bob")
+	if err != nil || !token.Expiry.Equal(expiry) || warned != "bob" {
+		t.Fatalf("Check: %+v %v, warned %q", token, err, warned)
+	}
+	if stored, _ := store.Load(ctx, "bob"); !stored.Expiry.Equal(expiry) {
+		t.Errorf("stored expiry %v, want %v", stored.Expiry, expiry)
+	}
+
+	status = "Expired"
+	if _, err := flow.Check(ctx, "bob"); !errors.Is(err, ErrTokenInactive) {
+		t.Errorf("Check of an expired token: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package ebaysvc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const bulkHeader = `<?xml version="1.0" encoding="UTF-8"?><BulkDataExchangeRequests xmlns="urn:ebay:apis:eBLBaseComponents"><Header><SiteID>0</SiteID><Version>1035</Version></Header>`
+
+func TestBulkWriter(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		w := NewBulkWriter(&buf, "0", compress)
+		for i := 0; i < 3; i++ {
+			request := &AddItemRequestType{Item: &ItemType{}}
+			request.Item.Title.Set("title")
+			if err := w.Write(request); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Write(); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		data := buf.Bytes()
+		if compress {
+			r, err := gzip.NewReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, err = ioutil.ReadAll(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		file := string(data)
+		if strings.Count(file, `<AddItemRequest xmlns="urn:ebay:apis:eBLBaseComponents">`) != 3 || !strings.HasPrefix(file, bulkHeader) || !strings.HasSuffix(file, "</BulkDataExchangeRequests>") {
+			t.Errorf("compress %v: %s", compress, file)
+		}
+	}
+
+	var empty bytes.Buffer
+	if err := NewBulkWriter(&empty, "0", false).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if empty.String() != bulkHeader+"</BulkDataExchangeRequests>" {
+		t.Errorf("empty file %s", empty.String())
+	}
+}
+
+func TestBulkReader(t *testing.T) {
+	responses := `<?xml version="1.0" encoding="UTF-8"?>
+<BulkDataExchangeResponses xmlns="urn:ebay:apis:eBLBaseComponents">
+ <AddItemResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Ack>Success</Ack><ItemID>1</ItemID></AddItemResponse>
+ <ReviseItemResponse><Ack>Success</Ack></ReviseItemResponse>
+ <AddItemResponse><Ack>Failure</Ack></AddItemResponse>
+</BulkDataExchangeResponses>`
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(responses))
+	zw.Close()
+
+	for _, in := range []io.Reader{strings.NewReader(responses), &compressed} {
+		r, err := NewBulkReader(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// ReviseItem is no call of the schema and returns an error.
+		var acks []string
+		var errs int
+		for {
+			response, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				errs++
+				continue
+			}
+			acks = append(acks, string(response.(*AddItemResponseType).Ack))
+		}
+		if strings.Join(acks, ",") != "Success,Failure" || errs != 1 {
+			t.Errorf("acks %v, %d errors", acks, errs)
+		}
+	}
+}
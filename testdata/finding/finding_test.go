@@ -0,0 +1,62 @@
+package ebaysvc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFindItemsByKeywords(t *testing.T) {
+	fake := NewFakeServer()
+	defer fake.Close()
+	APIGateway = fake.URL
+	if _, err := (&FindItemsByKeywordsRequestType{}).Request("EBAY-US"); err != ErrAPIAppIDNotSet {
+		t.Fatalf("Request without APIAppID: %v", err)
+	}
+
+	if err := (&FindItemsByKeywordsRequestType{}).Validate(); err == nil {
+		t.Fatal("no error for a request without keywords")
+	}
+
+	APIAppID = "app"
+	fake.HandleFindItemsByKeywords(func(r *FindItemsByKeywordsRequestType) (*FindItemsByKeywordsResponseType, error) {
+		if r.Keywords.Value() != "harry" {
+			t.Errorf("request %+v", r)
+		}
+		return &FindItemsByKeywordsResponseType{Ack: "Success", SearchResult: &SearchResult{Item: []SearchItem{{}}}}, nil
+	})
+	request := &FindItemsByKeywordsRequestType{}
+	request.Keywords.Set("harry")
+	response, err := request.Request("EBAY-US")
+	if err != nil || response.Ack != "Success" || len(response.SearchResult.Item) != 1 {
+		t.Fatalf("fake server: %v %+v", err, response)
+	}
+
+	var header http.Header
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		header, body = r.Header, string(data)
+		w.Write([]byte(`<findItemsByKeywordsResponse xmlns="http://www.ebay.com/marketplace/search/v1/services"><ack>Success</ack></findItemsByKeywordsResponse>`))
+	}))
+	defer srv.Close()
+	APIGateway = srv.URL
+	if _, err := request.Request("EBAY-GB"); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{
+		"X-EBAY-SOA-OPERATION-NAME":   "findItemsByKeywords",
+		"X-EBAY-SOA-SECURITY-APPNAME": "app",
+		"X-EBAY-SOA-GLOBAL-ID":        "EBAY-GB",
+		"X-EBAY-SOA-SERVICE-VERSION":  "1130",
+	} {
+		if header.Get(name) != value {
+			t.Errorf("header %s = %q, want %q", name, header.Get(name), value)
+		}
+	}
+	if !strings.Contains(body, `<findItemsByKeywordsRequest xmlns="http://www.ebay.com/marketplace/search/v1/services">`) {
+		t.Errorf("request body %s", body)
+	}
+}
@@ -0,0 +1,109 @@
+package ebaysvc
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_validSignature(t *testing.T) {
+	h := NewNotificationHandler("DEV-ID", "APP-ID", "CERT-ID")
+	tests := []struct {
+		timestamp string
+		signature string
+		want      bool
+	}{
+		{"2019-01-01T00:00:00.000Z", "EbKghLjV7QfECDcAHf0hJA==", true},
+		{"2019-01-01T00:00:01.000Z", "EbKghLjV7QfECDcAHf0hJA==", false},
+		{"2019-01-01T00:00:00.000Z", "", false},
+	}
+	for _, tt := range tests {
+		if got := h.validSignature(tt.timestamp, tt.signature); got != tt.want {
+			t.Errorf("validSignature(%q, %q) = %v, want %v", tt.timestamp, tt.signature, got, tt.want)
+		}
+	}
+}
+
+// notification returns a GetItem notification sent at timestamp, with the
+// event in the body unless it is empty.
+func notification(timestamp, signature, event string) string {
+	if event != "" {
+		event = "<NotificationEventName>" + event + "</NotificationEventName>"
+	}
+	return `<?xml version="1.0" encoding="UTF-8"?><soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Header>` +
+		`<ebl:RequesterCredentials soapenv:mustUnderstand="0" xmlns:ebl="urn:ebay:apis:eBLBaseComponents">` +
+		`<ebl:NotificationSignature xmlns:ebl="urn:ebay:apis:eBLBaseComponents">` + signature + `</ebl:NotificationSignature>` +
+		`</ebl:RequesterCredentials></soapenv:Header><soapenv:Body>` +
+		`<GetItemResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Timestamp>` + timestamp + `</Timestamp><Ack>Success</Ack>` + event +
+		`<HasMoreOrders>true</HasMoreOrders></GetItemResponse></soapenv:Body></soapenv:Envelope>`
+}
+
+func sign(timestamp string) string {
+	sum := md5.Sum([]byte(timestamp + "dev" + "app" + "cert"))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestNotificationHandler(t *testing.T) {
+	h := NewNotificationHandler("dev", "app", "cert")
+	h.MaxAge = 10 * time.Minute
+	var sold *GetItemResponseType
+	h.HandleItemSold(func(n *Notification, r *GetItemResponseType) error {
+		if n.Event != "ItemSold" {
+			t.Errorf("event %s, want ItemSold", n.Event)
+		}
+		sold = r
+		return nil
+	})
+	h.HandleItemListed(func(n *Notification, r *GetItemResponseType) error {
+		return errors.New("handler failed")
+	})
+	var unhandled string
+	h.Unhandled = func(n *Notification) error {
+		unhandled = n.Event
+		return nil
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	post := func(body, soapAction string) int {
+		request, _ := http.NewRequest("POST", srv.URL, strings.NewReader(body))
+		request.Header.Set("SOAPAction", soapAction)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	now := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	if code := post(notification(now, sign(now), ""), `"http://developer.ebay.com/notification/ItemSold"`); code != http.StatusOK || sold == nil || !sold.HasMoreOrders.Value() || sold.Ack != Ack_Success {
+		t.Fatalf("event in the SOAPAction: %d %+v", code, sold)
+	}
+
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	for _, tt := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{"event in the body", notification(now, sign(now), "ItemSold"), http.StatusOK},
+		{"bad signature", notification(now, "bad", "ItemSold"), http.StatusUnauthorized},
+		{"expired", notification(expired, sign(expired), "ItemSold"), http.StatusUnauthorized},
+		{"handler error", notification(now, sign(now), "ItemListed"), http.StatusInternalServerError},
+		{"unhandled", notification(now, sign(now), "ItemWon"), http.StatusOK},
+		{"no envelope", "garbage", http.StatusBadRequest},
+	} {
+		if code := post(tt.body, ""); code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, code, tt.want)
+		}
+	}
+	if unhandled != "ItemWon" {
+		t.Errorf("Unhandled got %q, want ItemWon", unhandled)
+	}
+}
@@ -0,0 +1,82 @@
+package ebaysvc
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSOAP(t *testing.T) {
+	var request *http.Request
+	var body string
+	status, answer := http.StatusOK, ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		request, body = r, string(data)
+		w.WriteHeader(status)
+		w.Write([]byte(answer))
+	}))
+	defer srv.Close()
+
+	client := NewEBayAPIClient()
+	client.URL = srv.URL
+	client.Header = &SOAPHeader{RequesterCredentials: &CustomSecurityHeaderType{}}
+	client.Header.RequesterCredentials.EBayAuthToken.Set("token")
+	answer = `<?xml version="1.0"?><soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body>` +
+		`<GetOrdersResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Ack>Success</Ack><HasMoreOrders>true</HasMoreOrders></GetOrdersResponse>` +
+		`</soapenv:Body></soapenv:Envelope>`
+	response, err := client.GetOrders(context.Background(), &GetOrdersRequestType{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Header.Get("SOAPAction") != `"urn:GetOrders"` || !strings.HasPrefix(request.Header.Get("Content-Type"), "text/xml") {
+		t.Errorf("SOAP 1.1 headers %v", request.Header)
+	}
+	for _, s := range []string{"http://schemas.xmlsoap.org/soap/envelope/", "<eBayAuthToken>token</eBayAuthToken>", "GetOrdersRequest"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("no %s in %s", s, body)
+		}
+	}
+	if response.Ack != Ack_Success || !response.HasMoreOrders.Value() {
+		t.Errorf("response %+v", response)
+	}
+
+	// A SOAP 1.2 fault with a detail declared in the WSDL.
+	client12 := NewEBayAPI12Client()
+	client12.URL = srv.URL
+	status = http.StatusInternalServerError
+	answer = `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+		`<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>x:Bad</env:Value></env:Subcode></env:Code>` +
+		`<env:Reason><env:Text xml:lang="en">bad order</env:Text></env:Reason>` +
+		`<env:Detail><OrderFault xmlns="urn:ebay:apis:eBLBaseComponents"><ErrorCode>42</ErrorCode></OrderFault></env:Detail>` +
+		`</env:Fault></env:Body></env:Envelope>`
+	_, err = client12.GetOrders(context.Background(), &GetOrdersRequestType{})
+	fault, ok := err.(*SOAPFault)
+	if !ok {
+		t.Fatalf("SOAP 1.2 fault: %T %v", err, err)
+	}
+	if !strings.Contains(body, "http://www.w3.org/2003/05/soap-envelope") || !strings.Contains(request.Header.Get("Content-Type"), `action="urn:GetOrders"`) {
+		t.Errorf("SOAP 1.2 request %v %s", request.Header, body)
+	}
+	detail, ok := fault.Detail.(*OrderFaultType)
+	if !ok || detail.ErrorCode.Value() != 42 || fault.Code != "env:Sender" || fault.String != "bad order" {
+		t.Errorf("SOAP 1.2 fault %+v %+v", fault, fault.Detail)
+	}
+
+	// An undeclared detail is kept raw.
+	answer = `<e:Envelope xmlns:e="http://schemas.xmlsoap.org/soap/envelope/"><e:Body><e:Fault>` +
+		`<faultcode>e:Server</faultcode><faultstring>boom</faultstring><detail><Other/></detail>` +
+		`</e:Fault></e:Body></e:Envelope>`
+	_, err = client.AddItem(context.Background(), &AddItemRequestType{})
+	if fault, ok := err.(*SOAPFault); !ok || fault.String != "boom" || fault.Detail != nil || fault.RawDetail != "<Other/>" {
+		t.Errorf("SOAP 1.1 fault %v", err)
+	}
+
+	status, answer = http.StatusBadGateway, "gateway"
+	if _, err = client.AddItem(context.Background(), &AddItemRequestType{}); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("no fault: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package ebaysvc
+
+import "testing"
+
+// hasValidationError reports whether err holds a ValidationError of typ for path.
+func hasValidationError(err error, path string, typ ValidationType) bool {
+	errs, _ := err.(ValidationErrors)
+	for _, e := range errs {
+		if e.Path == path && e.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDefaults(t *testing.T) {
+	request := NewAddItemRequest()
+	if request.Item == nil || request.Item.StartPrice == nil {
+		t.Fatal("required types are not created")
+	}
+	if request.Item.ListingType != ListingType_Chinese || request.Item.Quantity.Value() != 1 || request.Item.StartPrice.Unit.Value() != "each" {
+		t.Errorf("defaults not applied: %+v", request.Item)
+	}
+
+	request.Item.Quantity.Set(5)
+	request.ApplyDefaults()
+	if request.Item.Quantity.Value() != 5 {
+		t.Error("ApplyDefaults overwrote Item.Quantity")
+	}
+
+	request.Item.StartPrice.Unit.Set("dozen")
+	if err := request.Validate(); !hasValidationError(err, "Item.StartPrice.Unit", ValidationFixed) {
+		t.Errorf("no fixed value error for Item.StartPrice.Unit in %v", err)
+	}
+
+	orders := NewGetOrdersRequest()
+	orders.Pagination = &PaginationType{}
+	orders.ApplyDefaults()
+	if orders.Pagination.EntriesPerPage.Value() != 25 {
+		t.Errorf("Pagination.EntriesPerPage = %d, want 25", orders.Pagination.EntriesPerPage.Value())
+	}
+}
@@ -0,0 +1,29 @@
+package ebaysvc
+
+import "testing"
+
+func TestValidateEnumeration(t *testing.T) {
+	request := AddItemRequestType{Item: &ItemType{Currency: "XYZ", ListingType: "Nope"}}
+	errs, _ := request.Validate().(ValidationErrors)
+	enumerations := map[string]bool{}
+	for _, err := range errs {
+		if err.Type == ValidationEnumeration {
+			enumerations[err.Path] = true
+		}
+	}
+	for _, path := range []string{"Item.Currency", "Item.ListingType"} {
+		if !enumerations[path] {
+			t.Errorf("no enumeration error for %s in %v", path, errs)
+		}
+	}
+
+	// GetOrders narrows OrderStatus to the values valid for the call.
+	orders := GetOrdersRequestType{OrderStatus: "Shipped"}
+	if orders.Validate() == nil {
+		t.Error("no error for OrderStatus Shipped")
+	}
+	orders.OrderStatus = ""
+	if err := orders.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,38 @@
+package ebaysvc
+
+import (
+	"testing"
+)
+
+func TestFakeServer(t *testing.T) {
+	fake := NewFakeServer()
+	defer fake.Close()
+	APIGateway = fake.URL
+	fake.HandleAddItem(func(r *AddItemRequestType) (*AddItemResponseType, error) {
+		response := &AddItemResponseType{Ack: Ack_Success}
+		response.ItemID.Set("42-" + r.Item.Title.String())
+		return response, nil
+	})
+
+	request := &AddItemRequestType{Item: &ItemType{StartPrice: &AmountType{CurrencyID: "USD"}}}
+	request.Item.Title.Set("title")
+	request.Item.SKU.Set("sku")
+	request.Item.PaymentMethods.Append("PayPal")
+	request.Item.Currency = "USD"
+	request.Item.PictureURL.Append("http://example.com/1.jpg")
+	response, err := request.Request(AuthNAuth("token"), "0")
+	if err != nil || !response.Success() || response.ItemID.String() != "42-title" {
+		t.Fatalf("handled call: %v %+v", err, response)
+	}
+
+	// Requests failing Validate() never reach the handler.
+	response, err = (&AddItemRequestType{}).Request(AuthNAuth("token"), "0")
+	if err != nil || !response.Failure() || len(response.Errors) == 0 {
+		t.Fatalf("invalid request: %v %+v", err, response)
+	}
+
+	orders, err := (&GetOrdersRequestType{}).Request(AuthNAuth("token"), "0")
+	if err != nil || !orders.Failure() || orders.Errors[0].ErrorCode.String() != "NoHandler" {
+		t.Fatalf("call without handler: %v %+v", err, orders)
+	}
+}
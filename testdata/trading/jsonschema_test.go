@@ -0,0 +1,52 @@
+package ebaysvc
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// TestJSONSchemaKeys checks that the JSON keys of an encoded request are the
+// properties of the schema written with -jsonschema schemas.
+func TestJSONSchemaKeys(t *testing.T) {
+	data, err := os.ReadFile("schemas/AddItemRequest.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	request := NewAddItemRequest()
+	request.Item.StartPrice.Value.Set(1)
+	data, err = json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+	item := root["item"].(map[string]interface{})
+	startPrice := item["start_price"].(map[string]interface{})
+
+	for _, c := range []struct {
+		def    string
+		object map[string]interface{}
+	}{
+		{"AddItemRequestType", root},
+		{"ItemType", item},
+		{"AmountType", startPrice},
+	} {
+		for key := range c.object {
+			if _, ok := schema.Defs[c.def].Properties[key]; !ok {
+				t.Errorf("%s has no property %s", c.def, key)
+			}
+		}
+	}
+}
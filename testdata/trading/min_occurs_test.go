@@ -0,0 +1,42 @@
+package ebaysvc
+
+import "testing"
+
+// minOccursError returns the MinOccurs error for path in err, nil if there is none.
+func minOccursError(err error, path string) *ValidationError {
+	errs, _ := err.(ValidationErrors)
+	for i := range errs {
+		if errs[i].Path == path && errs[i].Type == ValidationMinOccurs {
+			return &errs[i]
+		}
+	}
+	return nil
+}
+
+func TestMinOccurs(t *testing.T) {
+	// The XSD minOccurs applies to an empty slice too.
+	request := NewAddItemRequest()
+	e := minOccursError(request.Validate(), "Item.PaymentMethods")
+	if e == nil || e.Limit != 1 || e.Value != 0 {
+		t.Errorf("empty Item.PaymentMethods: %+v", e)
+	}
+	request.Item.PaymentMethods.Append("PayPal")
+	if e := minOccursError(request.Validate(), "Item.PaymentMethods"); e != nil {
+		t.Errorf("Item.PaymentMethods with a value: %+v", e)
+	}
+
+	// The MinOccurs of GetOrders applies only once OrderID is used.
+	orders := GetOrdersRequestType{}
+	if err := orders.Validate(); err != nil {
+		t.Errorf("empty OrderID: %v", err)
+	}
+	orders.OrderID.Append("1")
+	e = minOccursError(orders.Validate(), "OrderID")
+	if e == nil || e.Limit != 2 || e.Value != 1 {
+		t.Errorf("one OrderID: %+v", e)
+	}
+	orders.OrderID.Append("2")
+	if err := orders.Validate(); err != nil {
+		t.Errorf("two OrderIDs: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package ebaysvc
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tokenSource returns the same token on every call.
+type tokenSource struct {
+	token *OAuthToken
+}
+
+func (s tokenSource) Token(ctx context.Context) (*OAuthToken, error) {
+	return s.token, nil
+}
+
+func TestOAuth(t *testing.T) {
+	var refreshes int
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		id, secret, _ := r.BasicAuth()
+		if id != "client" || secret != "secret" || r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" || r.Form.Get("scope") != "a b" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"bad credentials"}`)
+			return
+		}
+		refreshes++
+		// The first token expires within a minute and is refreshed right away.
+		expiresIn := 7200
+		if refreshes == 1 {
+			expiresIn = 30
+		}
+		fmt.Fprintf(w, `{"access_token":"access%d","expires_in":%d,"token_type":"User Access Token"}`, refreshes, expiresIn)
+	}))
+	defer tokens.Close()
+
+	var header, body string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		header, body = r.Header.Get("X-EBAY-API-IAF-TOKEN"), string(data)
+		fmt.Fprint(w, `<AddItemResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Ack>Success</Ack></AddItemResponse>`)
+	}))
+	defer api.Close()
+	APIGateway = api.URL
+
+	source := &RefreshingTokenSource{ClientID: "client", ClientSecret: "secret", RefreshToken: "refresh", Scopes: []string{"a", "b"}, TokenURL: tokens.URL}
+	request := &AddItemRequestType{RequesterCredentials: &XMLRequesterCredentialsType{}}
+	request.RequesterCredentials.EBayAuthToken.Set("stale")
+	for i, want := range []string{"access1", "access2", "access2"} {
+		if _, err := request.RequestContext(context.Background(), OAuth(source), "0"); err != nil {
+			t.Fatal(err)
+		}
+		if header != want || strings.Contains(body, "RequesterCredentials") {
+			t.Fatalf("request %d: token %q, body %s", i, header, body)
+		}
+	}
+
+	if _, err := request.Request(AuthNAuth("token"), "0"); err != nil || header != "" || !strings.Contains(body, "<eBayAuthToken>token</eBayAuthToken>") {
+		t.Fatalf("AuthNAuth: %v %q %s", err, header, body)
+	}
+	if got := request.RequesterCredentials.EBayAuthToken.Value(); got != "stale" {
+		t.Errorf("the request's eBayAuthToken changed to %q", got)
+	}
+	if _, err := (&AddItemRequestType{}).Request(OAuth(source), "0"); err != nil || strings.Contains(body, "RequesterCredentials") {
+		t.Fatalf("OAuth without RequesterCredentials: %v %s", err, body)
+	}
+	if _, err := request.Request(nil, "0"); err != ErrCredentialsNotSet {
+		t.Fatalf("no credentials: %v", err)
+	}
+
+	bad := &RefreshingTokenSource{ClientID: "client", ClientSecret: "wrong", RefreshToken: "refresh", TokenURL: tokens.URL}
+	if _, err := request.Request(OAuth(bad), "0"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("refresh failing: %v", err)
+	}
+	for _, token := range []*OAuthToken{nil, {}} {
+		if _, err := request.Request(OAuth(tokenSource{token}), "0"); err != ErrOAuthTokenNotSet {
+			t.Errorf("token %+v: %v", token, err)
+		}
+	}
+}
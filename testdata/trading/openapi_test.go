@@ -0,0 +1,118 @@
+package ebaysvc
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestOpenAPI checks the document written with -openapi openapi.json.
+func TestOpenAPI(t *testing.T) {
+	data, err := os.ReadFile("openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document struct {
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	// One POST operation per exported call, the fields of Request.
+	requests := reflect.TypeOf(Request{})
+	if len(document.Paths) != requests.NumField() {
+		t.Errorf("%d paths for %d calls", len(document.Paths), requests.NumField())
+	}
+	for i := 0; i < requests.NumField(); i++ {
+		call := strings.TrimSuffix(requests.Field(i).Name, "Request")
+		operations := document.Paths["/"+call]
+		if _, ok := operations["post"]; !ok || len(operations) != 1 {
+			t.Errorf("/%s: %v, want a post operation", call, operations)
+		}
+	}
+
+	// Every $ref resolves to a component schema.
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if _, ok := document.Components.Schemas[name]; !ok || name == ref {
+					t.Errorf("$ref %s does not resolve", ref)
+				}
+			}
+			for _, value := range v {
+				walk(value)
+			}
+		case []interface{}:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+	var raw interface{}
+	json.Unmarshal(data, &raw)
+	walk(raw)
+
+	// The JSON encoding of a request and a response only has keys the
+	// schemas define.
+	request := NewAddItemRequest()
+	request.RequesterCredentials = &XMLRequesterCredentialsType{}
+	request.RequesterCredentials.EBayAuthToken.Set("token")
+	request.ErrorLanguage.Set("en_US")
+	request.Item.Title.Set("title")
+	request.Item.SKU.Set("sku")
+	request.Item.PictureURL.Append("http://example.com/1.jpg")
+	request.Item.StartPrice.Value.Set(1.5)
+	response := GetOrdersResponseType{Ack: Ack_Success, OrderArray: &OrderArrayType{Order: []OrderType{{Item: &ItemType{}}}}}
+	response.OrderArray.Order[0].OrderID.Set("1")
+	response.OrderArray.Order[0].Item.Title.Set("title")
+	for _, c := range []struct {
+		schema string
+		value  interface{}
+	}{
+		{"AddItemRequestType", request},
+		{"GetOrdersResponseType", response},
+	} {
+		data, err := json.Marshal(c.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var encoded interface{}
+		json.Unmarshal(data, &encoded)
+		checkProperties(t, document.Components.Schemas, document.Components.Schemas[c.schema], encoded, c.schema)
+	}
+}
+
+// checkProperties checks that the keys of the objects in value are
+// properties of schema.
+func checkProperties(t *testing.T, schemas map[string]map[string]interface{}, schema map[string]interface{}, value interface{}, path string) {
+	t.Helper()
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, v := range value {
+			property, ok := properties[key].(map[string]interface{})
+			if !ok {
+				t.Errorf("%s has no property %s", path, key)
+				continue
+			}
+			checkProperties(t, schemas, property, v, path+"."+key)
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for _, v := range value {
+			checkProperties(t, schemas, items, v, path+"[]")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ebaysvc
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	fake := NewFakeServer()
+	defer fake.Close()
+	APIGateway = fake.URL
+	fake.HandleGetOrders(func(r *GetOrdersRequestType) (*GetOrdersResponseType, error) {
+		if r.RequesterCredentials == nil || r.RequesterCredentials.EBayAuthToken.Value() != "token" {
+			t.Errorf("page %d sent without the token: %+v", r.Pagination.PageNumber.Value(), r.RequesterCredentials)
+		}
+		response := &GetOrdersResponseType{Ack: Ack_Success}
+		response.PageNumber = r.Pagination.PageNumber
+		response.HasMoreOrders.Set(r.Pagination.PageNumber.Value() < 3)
+		return response, nil
+	})
+
+	var pages []int64
+	for page, err := range (&GetOrdersRequestType{}).All(context.Background(), AuthNAuth("token"), "0") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page.PageNumber.Value())
+	}
+	if len(pages) != 3 || pages[0] != 1 || pages[2] != 3 {
+		t.Fatalf("pages %v, want [1 2 3]", pages)
+	}
+
+	// Every page is sent with the token of the credentials, the request keeps its own.
+	request := &GetOrdersRequestType{RequesterCredentials: &XMLRequesterCredentialsType{}}
+	request.RequesterCredentials.EBayAuthToken.Set("stale")
+	for _, err := range request.All(context.Background(), AuthNAuth("token"), "0") {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := request.RequesterCredentials.EBayAuthToken.Value(); got != "stale" || request.Pagination != nil {
+		t.Errorf("All changed the request: %q %+v", got, request.Pagination)
+	}
+
+	// Breaking out of the loop must not panic.
+	for range (&GetOrdersRequestType{}).All(context.Background(), AuthNAuth("token"), "0") {
+		break
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range (&GetOrdersRequestType{}).All(ctx, AuthNAuth("token"), "0") {
+		if err == nil {
+			t.Fatal("no error for a cancelled context")
+		}
+	}
+}
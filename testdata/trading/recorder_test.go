@@ -0,0 +1,91 @@
+package ebaysvc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-EBAY-API-CALL-NAME") == "Unknown" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<AddItemResponse xmlns="urn:ebay:apis:eBLBaseComponents"><Ack>Success</Ack><ItemID>123</ItemID></AddItemResponse>`)
+	}))
+	defer srv.Close()
+	APIGateway = srv.URL
+	defer func() { HTTPClient = &http.Client{} }()
+
+	dir := t.TempDir()
+	HTTPClient = &http.Client{Transport: NewRecorder(RecorderRecord, dir)}
+	response, err := (&AddItemRequestType{}).Request(AuthNAuth("token"), "0")
+	if err != nil || response.ItemID.String() != "123" {
+		t.Fatalf("recording: %v %+v", err, response)
+	}
+	unknown := func() (*http.Response, error) {
+		request, _ := http.NewRequest("POST", srv.URL, strings.NewReader("<UnknownRequest/>"))
+		request.Header.Set("X-EBAY-API-CALL-NAME", "Unknown")
+		return HTTPClient.Do(request)
+	}
+	if _, err := unknown(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	// Fixtures match requests whatever their credentials.
+	HTTPClient = &http.Client{Transport: NewRecorder(RecorderReplay, dir)}
+	response, err = (&AddItemRequestType{}).Request(AuthNAuth("other-token"), "0")
+	if err != nil || response.ItemID.String() != "123" || !response.Success() {
+		t.Fatalf("replaying: %v %+v", err, response)
+	}
+
+	// Replayed responses carry the status like net/http's.
+	if got, err := unknown(); err != nil || got.Status != "404 Not Found" || got.StatusCode != http.StatusNotFound {
+		t.Fatalf("replaying a 404: %v %+v", err, got)
+	}
+
+	request := &AddItemRequestType{}
+	request.ErrorLanguage.Set("de")
+	if _, err = request.Request(AuthNAuth("token"), "0"); !errors.Is(err, ErrFixtureNotFound) {
+		t.Fatalf("replaying an unrecorded request: %v, want ErrFixtureNotFound", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// TestRecorderRequest checks that recording leaves the request to the
+// underlying transport as it is.
+func TestRecorderRequest(t *testing.T) {
+	request, _ := http.NewRequest("POST", "http://example.com", strings.NewReader("<GetOrdersRequest/>"))
+	request.Header.Set("X-EBAY-API-CALL-NAME", "GetOrders")
+	body := request.Body
+
+	recorder := NewRecorder(RecorderRecord, t.TempDir())
+	recorder.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r == request {
+			t.Error("the request is sent itself, not a clone")
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		if string(data) != "<GetOrdersRequest/>" || r.Header.Get("X-EBAY-API-CALL-NAME") != "GetOrders" {
+			t.Errorf("sent %v %s", r.Header, data)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("<GetOrdersResponse/>"))}, nil
+	})
+	response, err := recorder.RoundTrip(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Body != body || response.Request != request {
+		t.Errorf("request changed: body %v, response request %p, want %p", request.Body, response.Request, request)
+	}
+}
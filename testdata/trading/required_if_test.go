@@ -0,0 +1,17 @@
+package ebaysvc
+
+import "testing"
+
+func TestRequiredIf(t *testing.T) {
+	request := NewAddItemRequest()
+	request.Item.ListingType = ListingType_FixedPriceItem
+	request.Item.Quantity = NullInt64{}
+	if err := request.Validate(); !hasValidationError(err, "Item.Quantity", ValidationRequiredIf) {
+		t.Errorf("no required if error for Item.Quantity in %v", err)
+	}
+
+	request.Item.Quantity.Set(3)
+	if err := request.Validate(); hasValidationError(err, "Item.Quantity", ValidationRequiredIf) {
+		t.Errorf("required if error for a set Item.Quantity: %v", err)
+	}
+}
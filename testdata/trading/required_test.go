@@ -0,0 +1,15 @@
+package ebaysvc
+
+import "testing"
+
+// TestRequired checks fields listed with AllCallsExcept, which AddItem takes
+// as given by their RequiredInput.
+func TestRequired(t *testing.T) {
+	err := NewAddItemRequest().Validate()
+	if !hasValidationError(err, "Item.SKU", ValidationRequired) {
+		t.Errorf("no required error for Item.SKU in %v", err)
+	}
+	if hasValidationError(err, "Item.Location", ValidationRequired) {
+		t.Errorf("required error for the optional Item.Location in %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package ebaysvc
+
+import "testing"
+
+func TestResponseValidate(t *testing.T) {
+	response := AddItemResponseType{Ack: Ack_Success, Currency: "XYZ"}
+	response.ItemID.Set("1")
+	if err := response.Validate(); err == nil {
+		t.Fatal("no error for currency XYZ")
+	}
+	response.Currency = "USD"
+	if err := response.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
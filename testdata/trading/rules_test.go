@@ -0,0 +1,24 @@
+package ebaysvc
+
+import "testing"
+
+// TestRulesAllowedValues checks the allowedValues of testdata/trading.rules.json
+// on a NullString field.
+func TestRulesAllowedValues(t *testing.T) {
+	request := GetOrdersRequestType{}
+	if err := request.Validate(); err != nil {
+		t.Fatalf("unset ErrorLanguage: %v", err)
+	}
+	request.ErrorLanguage.Set("de_DE")
+	if err := request.Validate(); err != nil {
+		t.Fatalf("allowed ErrorLanguage: %v", err)
+	}
+	request.ErrorLanguage.Set("fr_FR")
+	err := request.Validate()
+	if !hasValidationError(err, "ErrorLanguage", ValidationOnlyTheseValues) {
+		t.Fatalf("no error for ErrorLanguage fr_FR in %v", err)
+	}
+	if got := err.(ValidationErrors)[0].Value; got != "fr_FR" {
+		t.Errorf("Value = %#v, want \"fr_FR\"", got)
+	}
+}
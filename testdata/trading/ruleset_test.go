@@ -0,0 +1,82 @@
+package ebaysvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// exportedRule is a rule of the -rules-out file rules.json.
+type exportedRule struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+	IfSet bool            `json:"ifSet"`
+}
+
+// TestRuleSet checks that the rules exported with -rules-out are the ones
+// Validate() checks.
+func TestRuleSet(t *testing.T) {
+	data, err := os.ReadFile("rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ruleSet map[string]map[string][]exportedRule
+	if err := json.Unmarshal(data, &ruleSet); err != nil {
+		t.Fatal(err)
+	}
+
+	request := NewAddItemRequest()
+	request.Item.Title.Set(strings.Repeat("x", 90))
+	request.Item.Currency = "XYZ"
+	request.Item.ListingType = ListingType_FixedPriceItem
+	request.Item.Quantity = NullInt64{}
+	errs, _ := request.Validate().(ValidationErrors)
+
+	index := regexp.MustCompile(`\[\d+\]`)
+	found := map[string]bool{}
+	for _, e := range errs {
+		path := index.ReplaceAllString(e.Path, "[]")
+		var rule *exportedRule
+		for i, r := range ruleSet["AddItem"][path] {
+			if r.Type == string(e.Type) {
+				rule = &ruleSet["AddItem"][path][i]
+			}
+		}
+		if rule == nil {
+			t.Errorf("%s %s is not exported: %v", path, e.Type, ruleSet["AddItem"][path])
+			continue
+		}
+		found[path+" "+string(e.Type)] = true
+
+		switch e.Type {
+		case ValidationMaxLength:
+			if string(rule.Value) != fmt.Sprint(e.Limit) {
+				t.Errorf("%s MaxLength: exported %s, Validate() %v", path, rule.Value, e.Limit)
+			}
+		case ValidationEnumeration:
+			var values []string
+			json.Unmarshal(rule.Value, &values)
+			if fmt.Sprint(values) != fmt.Sprint(e.Limit) {
+				t.Errorf("%s Enumeration: exported %v, Validate() %v", path, values, e.Limit)
+			}
+		case ValidationRequiredIf:
+			var condition struct {
+				Field  string   `json:"field"`
+				Values []string `json:"values"`
+			}
+			json.Unmarshal(rule.Value, &condition)
+			if want := condition.Field + " is " + strings.Join(condition.Values, " or "); want != e.Limit {
+				t.Errorf("%s RequiredIf: exported %q, Validate() %v", path, want, e.Limit)
+			}
+		}
+	}
+
+	for _, want := range []string{"Item.SKU Required", "Item.Title MaxLength", "Item.Currency Enumeration", "Item.Quantity RequiredIf"} {
+		if !found[want] {
+			t.Errorf("Validate() did not report %s: %v", want, errs)
+		}
+	}
+}
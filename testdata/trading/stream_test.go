@@ -0,0 +1,40 @@
+package ebaysvc
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStreamOrder(t *testing.T) {
+	fake := NewFakeServer()
+	defer fake.Close()
+	APIGateway = fake.URL
+	fake.HandleGetOrders(func(r *GetOrdersRequestType) (*GetOrdersResponseType, error) {
+		response := &GetOrdersResponseType{Ack: Ack_Success, OrderArray: &OrderArrayType{}}
+		for i := 0; i < 5; i++ {
+			order := OrderType{OrderStatus: OrderStatus_Active}
+			order.OrderID.Set(strings.Repeat("x", i))
+			response.OrderArray.Order = append(response.OrderArray.Order, order)
+		}
+		response.PaginationResult = &PaginationResultType{}
+		response.PaginationResult.TotalNumberOfPages.Set(7)
+		return response, nil
+	})
+
+	n := 0
+	response, err := (&GetOrdersRequestType{}).StreamOrder(context.Background(), AuthNAuth("token"), "0", func(order *OrderType) error {
+		if order.OrderID.String() != strings.Repeat("x", n) || order.OrderStatus != OrderStatus_Active {
+			t.Errorf("order %d: %+v", n, order)
+		}
+		n++
+		return nil
+	})
+	if err != nil || n != 5 {
+		t.Fatalf("streamed %d orders: %v", n, err)
+	}
+	// The rest of the response is decoded, the streamed orders are not kept.
+	if !response.Success() || response.PaginationResult.TotalNumberOfPages.Value() != 7 || len(response.OrderArray.Order) != 0 {
+		t.Fatalf("response %+v", response)
+	}
+}
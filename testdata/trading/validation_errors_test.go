@@ -0,0 +1,37 @@
+package ebaysvc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidationErrors(t *testing.T) {
+	request := AddItemRequestType{Item: &ItemType{StartPrice: &AmountType{}}}
+	request.Item.Title.Set(strings.Repeat("x", 90))
+	request.Item.Quantity.Set(0)
+	errs, ok := request.Validate().(ValidationErrors)
+	if !ok {
+		t.Fatalf("Validate() returned %T, want ValidationErrors", request.Validate())
+	}
+
+	// Value holds the plain value of the field, not its Null* type.
+	values := map[string]interface{}{}
+	for _, err := range errs {
+		values[err.Path] = err.Value
+	}
+	for path, want := range map[string]interface{}{
+		"Item.Title":                 strings.Repeat("x", 90),
+		"Item.Quantity":              int64(0),
+		"Item.Currency":              nil,
+		"Item.PictureURL":            nil,
+		"Item.StartPrice.CurrencyID": nil,
+	} {
+		got, ok := values[path]
+		if !ok {
+			t.Errorf("no error for %s in %v", path, errs)
+		} else if got != want {
+			t.Errorf("%s: Value = %#v, want %#v", path, got, want)
+		}
+	}
+
+}
@@ -3,6 +3,10 @@ package main
 import (
 	"fmt"
 	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -13,6 +17,7 @@ type TypeDetails struct {
 	IsPointer  bool
 	IsSlice    bool
 	SimpleType bool
+	Enum       string
 	key        string
 }
 
@@ -27,8 +32,54 @@ const (
 	ValTypMin
 	ValTypMax
 	ValTypDefault
+	ValTypReturned
+	ValTypEnumeration
+	ValTypMinOccurs
+	ValTypFixed
+	ValTypRequiredIf
 )
 
+var validationTypeNames = map[ValidationType]string{
+	ValTypMaxOccurs:       "MaxOccurs",
+	ValTypAllValuesExcept: "AllValuesExcept",
+	ValTypOnlyTheseValues: "OnlyTheseValues",
+	ValTypMaxLength:       "MaxLength",
+	ValTypRequired:        "Required",
+	ValTypMin:             "Min",
+	ValTypMax:             "Max",
+	ValTypDefault:         "Default",
+	ValTypReturned:        "Returned",
+	ValTypEnumeration:     "Enumeration",
+	ValTypMinOccurs:       "MinOccurs",
+	ValTypFixed:           "Fixed",
+	ValTypRequiredIf:      "RequiredIf",
+}
+
+// loopKey matches the index variables of the loops emitted by element.Validator.
+var loopKey = regexp.MustCompile(`\[(i\d+)\]`)
+
+func (t ValidationType) String() string {
+	return validationTypeNames[t]
+}
+
+// validationTypes returns the ValidationType constants of the generated package.
+func validationTypes() string {
+	var types []int
+	for t := range validationTypeNames {
+		types = append(types, int(t))
+	}
+	sort.Ints(types)
+
+	b := NewBuffer()
+	b.Sprintf("const (\r\n")
+	for _, t := range types {
+		name := ValidationType(t).String()
+		b.Sprintf("\tValidation%[1]s ValidationType = \"%[1]s\"\r\n", name)
+	}
+	b.Sprintf(")\r\n")
+	return b.String()
+}
+
 func (t TypeDetails) Path(path string) string {
 	base := path + "." + UpperFirstLetter(t.Field)
 	if t.key != "" {
@@ -46,110 +97,13 @@ func (t *TypeDetails) Key(key string) *TypeDetails {
 	return t
 }
 
-type ValidationParam [2]string
-
-func (p ValidationParam) Condition() string {
-	return p[0]
-}
-
-func (p ValidationParam) Error() string {
-	return p[1]
-}
-
-func (p *ValidationParam) New(condition, err string) {
-	p[0] = condition
-	p[1] = err
-}
-
-func (t TypeDetails) ValidationParams(rule ValidationRule, path string) (p ValidationParam) {
-	fpath := path + "." + UpperFirstLetter(t.Field)
-	setterChecker := func() bool {
-		if k := t.IsSet(fpath); k != "" {
-			p.New(
-				k,
-				fmt.Sprintf("field %s must be set", t.Field))
-			return true
-		}
-		return false
-	}
-	switch rule.Type {
-	case ValTypMaxOccurs:
-		if t.IsSlice {
-			p.New(
-				fmt.Sprintf("len(%[1]s) > %[2]v", t.Path(path), rule.Value),
-				fmt.Sprintf("() field %s must be between 0 and %v", fpath, rule.Value))
-		}
-	case ValTypAllValuesExcept:
-		parts := strings.Split(rule.Value.(string), ",")
-		for i := range parts {
-			parts[i] = strings.TrimSpace(parts[i])
-		}
-		p.New(
-			fmt.Sprintf("contains([]string{\"%[2]s\"}, string(%[1]s))", t.Path(path), strings.Join(parts, "\", \"")),
-			fmt.Sprintf("field %s contains invalid value", fpath))
-	case ValTypOnlyTheseValues:
-		parts := strings.Split(rule.Value.(string), ",")
-		for i := range parts {
-			parts[i] = strings.TrimSpace(parts[i])
-		}
-		p.New(
-			fmt.Sprintf("!contains([]string{\"%[2]s\"}, string(%[1]s))", t.Path(path), strings.Join(parts, "\", \"")),
-			fmt.Sprintf("field %s contains invalid value", fpath))
-	case ValTypMaxLength:
-		valueInt, err1 := rule.ValueInt()
-		if err1 != nil {
-			log.Printf("Could not parse MaxLength value for %s, skipping validation line. Error: `%s`\r\nValue: `%v`", fpath, err1, rule.Value)
-		}
-		p.New(
-			fmt.Sprintf(t.CheckMaxLenght(t.Path(path), valueInt)),
-			fmt.Sprintf("field %s must be between 1 and %d characters long", fpath, valueInt))
-	case ValTypRequired:
-		if !setterChecker() {
-			log.Fatalf("ValTypRequired: validation handling is not implemented for %s %v %+v", fpath, rule, t)
-		}
-	case ValTypMin:
-		switch t.AliasFor.GoType() {
-		case "NullFloat64", "NullInt64":
-			valueInt, err1 := rule.ValueInt()
-			if err1 != nil {
-				log.Printf("Could not parse Min value for %s, skipping validation line. Error: `%s`\r\nValue: `%v`", t.Path(path), err1, rule.Value)
-			}
-			p.New(
-				fmt.Sprintf("%[1]s.Valid && %[1]s.Value() < %[2]d", t.Path(path), valueInt),
-				fmt.Sprintf("(max) field %s must be more than %d of length", fpath, valueInt))
-		default:
-			log.Fatalf("ValTypMin: validation handling is not implemented for %s %v %+v", fpath, rule, t)
-		}
-	case ValTypMax:
-		switch t.AliasFor.GoType() {
-		case "NullFloat64", "NullInt64":
-			valueInt, err1 := rule.ValueInt()
-			if err1 != nil {
-				log.Printf("Could not parse Max value for %s, skipping validation line. Error: `%s`\r\nValue: `%v`", t.Path(path), err1, rule.Value)
-			}
-			p.New(
-				fmt.Sprintf("%[1]s.Valid && %[1]s.Value() > %[2]d", t.Path(path), valueInt),
-				fmt.Sprintf("ValTypMax: field %s must be between 1 and %d", fpath, valueInt))
-		default:
-			log.Fatal("ValTypMax: validation handling is not implemented for %s %v %+v", fpath, rule, t)
-		}
-	default:
-		log.Fatal("validation handling is not implemented")
-	}
-	if len(p) == 0 {
-		log.Fatalf("validation did not implemenet any handling for %+v, rule: %+v", t, rule)
-	}
-	return
-}
-
 func (t TypeDetails) ValidationString(rule ValidationRule, path string) string {
 	var condition []string
-	var err string
+	var limit, value string
 	fpath := path + "." + UpperFirstLetter(t.Field)
 	setterChecker := func() bool {
 		if k := t.IsSet(fpath); k != "" {
 			condition = append(condition, k)
-			err = fmt.Sprintf("field %s must be set", t.Field)
 			return true
 		}
 		return false
@@ -158,24 +112,34 @@ func (t TypeDetails) ValidationString(rule ValidationRule, path string) string {
 	case ValTypMaxOccurs:
 		if t.IsSlice {
 			condition = append(condition, fmt.Sprintf("len(%[1]s) > %[2]v", t.Path(path), rule.Value))
-			err = fmt.Sprintf("field %s must be between 0 and %v", fpath, rule.Value)
+			limit = fmt.Sprint(rule.Value)
+			value = fmt.Sprintf("len(%s)", t.Path(path))
 		} else {
 			return ""
 		}
+	case ValTypMinOccurs:
+		if !t.IsSlice {
+			return ""
+		}
+		condition = append(condition, fmt.Sprintf("len(%[1]s) < %[2]v", t.Path(path), rule.Value))
+		limit = fmt.Sprint(rule.Value)
+		value = fmt.Sprintf("len(%s)", t.Path(path))
 	case ValTypAllValuesExcept:
 		parts := strings.Split(rule.Value.(string), ",")
 		for i := range parts {
 			parts[i] = strings.TrimSpace(parts[i])
 		}
-		condition = append(condition, fmt.Sprintf("contains([]string{\"%[2]s\"}, string(%[1]s))", t.Path(path), strings.Join(parts, "\", \"")))
-		err = fmt.Sprintf("field %s contains invalid value", fpath)
+		limit = fmt.Sprintf("[]string{\"%s\"}", strings.Join(parts, "\", \""))
+		value = t.StringValue(t.Path(path))
+		condition = append(condition, fmt.Sprintf("contains(%s, %s)", limit, value))
 	case ValTypOnlyTheseValues:
 		parts := strings.Split(rule.Value.(string), ",")
 		for i := range parts {
 			parts[i] = strings.TrimSpace(parts[i])
 		}
-		condition = append(condition, fmt.Sprintf("!contains([]string{\"%[2]s\"}, string(%[1]s))", t.Path(path), strings.Join(parts, "\", \"")))
-		err = fmt.Sprintf("field %s contains invalid value", fpath)
+		limit = fmt.Sprintf("[]string{\"%s\"}", strings.Join(parts, "\", \""))
+		value = t.StringValue(t.Path(path))
+		condition = append(condition, fmt.Sprintf("%[1]s != \"\" && !contains(%[2]s, %[1]s)", value, limit))
 	case ValTypMaxLength:
 		valueInt, err1 := rule.ValueInt()
 		if err1 != nil {
@@ -184,7 +148,8 @@ func (t TypeDetails) ValidationString(rule ValidationRule, path string) string {
 		}
 		if k := t.CheckMaxLenght(t.Path(path), valueInt); k != "" {
 			condition = append(condition, k)
-			err = fmt.Sprintf("field %s must be between 1 and %d characters long", fpath, valueInt)
+			limit = strconv.Itoa(valueInt)
+			value = t.Value(t.Path(path))
 		} else {
 			return ""
 		}
@@ -192,22 +157,43 @@ func (t TypeDetails) ValidationString(rule ValidationRule, path string) string {
 		if !setterChecker() {
 			log.Fatalf("ValTypRequired: validation handling is not implemented for %s %v %+v", fpath, rule, t)
 		}
+	case ValTypReturned:
+		if !setterChecker() {
+			log.Fatalf("ValTypReturned: validation handling is not implemented for %s %v %+v", fpath, rule, t)
+		}
+	case ValTypEnumeration:
+		limit = fmt.Sprintf("%sList[:]", rule.Value)
+		value = t.StringValue(t.Path(path))
+		condition = append(condition, fmt.Sprintf("%[1]s != \"\" && !contains(%[2]s, %[1]s)", value, limit))
+	case ValTypRequiredIf:
+		c := rule.Value.(requiredIf)
+		condition = append(condition, c.Expr)
+		if !setterChecker() {
+			log.Fatalf("ValTypRequiredIf: validation handling is not implemented for %s %v %+v", fpath, rule, t)
+		}
+		limit = strconv.Quote(c.Condition.String())
+	case ValTypFixed:
+		limit = strconv.Quote(rule.Value.(string))
+		value = t.StringValue(t.Path(path))
+		condition = append(condition, fmt.Sprintf("%[1]s != \"\" && %[1]s != %[2]s", value, limit))
 	case ValTypMin:
-		valueInt, err1 := rule.ValueInt()
+		valueFloat, err1 := rule.ValueFloat()
 		if err1 != nil {
 			log.Printf("Could not parse Min value for %s, skipping validation line. Error: `%s`\r\nValue: `%v`", t.Path(path), err1, rule.Value)
 			return ""
 		}
-		condition = append(condition, t.Min(t.Path(path), valueInt))
-		err = fmt.Sprintf("(max) field %s must be more than %d of length", fpath, valueInt)
+		condition = append(condition, t.Min(t.Path(path), valueFloat))
+		limit = formatLimit(valueFloat)
+		value = t.Value(t.Path(path))
 	case ValTypMax:
-		valueInt, err1 := rule.ValueInt()
+		valueFloat, err1 := rule.ValueFloat()
 		if err1 != nil {
 			log.Printf("Could not parse Max value for %s, skipping validation line. Error: `%s`\r\nValue: `%v`", t.Path(path), err1, rule.Value)
 			return ""
 		}
-		condition = append(condition, t.Max(t.Path(path), valueInt))
-		err = fmt.Sprintf("ValTypMax: field %s must be between 1 and %d", fpath, valueInt)
+		condition = append(condition, t.Max(t.Path(path), valueFloat))
+		limit = formatLimit(valueFloat)
+		value = t.Value(t.Path(path))
 	default:
 		log.Fatal("validation handling is not implemented")
 	}
@@ -215,7 +201,30 @@ func (t TypeDetails) ValidationString(rule ValidationRule, path string) string {
 		log.Fatalf("validation did not implemenet any handling for %+v, rule: %+v", t, rule)
 	}
 
-	return fmt.Sprintf("if %s { return errors.New(\"%s\") }\r\n", strings.Join(condition, " && "), err)
+	if rule.SkipsUnset() {
+		condition = append([]string{t.HasValue(fpath)}, condition...)
+	}
+
+	fields := fmt.Sprintf("Path: %s, Type: Validation%s", t.FieldPath(path), rule.Type)
+	if limit != "" {
+		fields += ", Limit: " + limit
+	}
+	if value != "" {
+		fields += ", Value: " + value
+	}
+	return fmt.Sprintf("if %s {\r\nerrs = append(errs, ValidationError{%s})\r\n}\r\n", strings.Join(condition, " && "), fields)
+}
+
+// FieldPath returns a Go expression building the path of the field as shown
+// in ValidationError, e.g. "Item.PictureURL[" + strconv.Itoa(i1) + "]".
+func (t TypeDetails) FieldPath(path string) string {
+	base := path + "." + UpperFirstLetter(t.Field)
+	if t.key != "" {
+		base += "[" + t.key + "]"
+	}
+	base = strings.TrimPrefix(strings.TrimPrefix(base, "x"), ".")
+	expr := "\"" + loopKey.ReplaceAllString(base, `[" + strconv.Itoa($1) + "]`) + "\""
+	return strings.Replace(expr, ` + ""`, "", -1)
 }
 
 func (t TypeDetails) T() string {
@@ -255,7 +264,7 @@ func (t TypeDetails) IsSet(path string) string {
 	switch t.T() {
 	case "string":
 		return fmt.Sprintf("%s == \"\"", path)
-	case "int32", "int64":
+	case "int32", "int64", "float32", "float64":
 		return fmt.Sprintf("%s == 0", path)
 	case "NullString", "NullFloat64", "NullInt64", "NullBool":
 		return fmt.Sprintf("!%s.Valid", path)
@@ -264,28 +273,154 @@ func (t TypeDetails) IsSet(path string) string {
 	return ""
 }
 
-func (t TypeDetails) Min(path string, value int) string {
+// JSONType returns the JSON type of a simple field ("string", "integer",
+// "number", "boolean" or "base64" for []byte) and whether it is encoded as
+// null when unset, like the Null* types are.
+func (t TypeDetails) JSONType() (jsonType string, nullable bool) {
+	nullable = strings.HasPrefix(t.T(), "Null")
+	switch strings.TrimPrefix(t.T(), "Null") {
+	case "String", "string":
+		return "string", nullable
+	case "Int64", "int32", "int64":
+		return "integer", nullable
+	case "Float64", "float32", "float64":
+		return "number", nullable
+	case "Bool", "bool":
+		return "boolean", nullable
+	case "[]byte":
+		return "base64", false
+	}
+	return "", false
+}
+
+// HasValue returns the condition telling the field holds a value, the
+// opposite of IsSet.
+func (t TypeDetails) HasValue(path string) string {
+	if t.key != "" {
+		path = fmt.Sprintf("%s[%s]", path, t.key)
+	} else if t.IsPointer {
+		return fmt.Sprintf("%s != nil", path)
+	} else if t.IsSlice {
+		return fmt.Sprintf("len(%s) > 0", path)
+	}
+	switch t.T() {
+	case "string":
+		return fmt.Sprintf("%s != \"\"", path)
+	case "int32", "int64", "float32", "float64":
+		return fmt.Sprintf("%s != 0", path)
+	case "NullString", "NullFloat64", "NullInt64", "NullBool":
+		return fmt.Sprintf("%s.Valid", path)
+	}
+	log.Fatalf("TypeDetails.HasValue: unknown %s : %+v", t.T(), t)
+	return ""
+}
+
+// StringValue returns the value of the field at path as a string expression.
+func (t TypeDetails) StringValue(path string) string {
+	switch t.T() {
+	case "string":
+		return "string(" + path + ")"
+	case "NullString", "NullInt64", "NullFloat64", "NullBool":
+		return path + ".String()"
+	}
+	return "fmt.Sprint(" + path + ")"
+}
+
+// Value returns the plain value of the field at path, unwrapping the Null*
+// types and the amount of an AmountType.
+func (t TypeDetails) Value(path string) string {
+	switch t.T() {
+	case "NullString", "NullInt64", "NullFloat64", "NullBool":
+		return path + ".Value()"
+	case "AmountType":
+		return path + ".Value.Value()"
+	}
+	return path
+}
+
+// Assign returns the statement setting the field to value, a default or
+// fixed value taken from the schema.
+func (t TypeDetails) Assign(path, value string) (string, error) {
+	fpath := path + "." + UpperFirstLetter(t.Field)
+	if t.IsPointer || t.IsSlice {
+		return "", fmt.Errorf("cannot assign %s", t.T())
+	}
+	switch t.T() {
+	case "string":
+		if t.Enum != "" {
+			if x, ok := FindSimple(t.Enum); ok && !x.HasValue(value) {
+				return "", fmt.Errorf("%q is not a %s value", value, t.Enum)
+			}
+		}
+		return fmt.Sprintf("%s = %q", fpath, value), nil
+	case "NullString":
+		return fmt.Sprintf("%s.Set(%q)", fpath, value), nil
+	case "int32", "int64", "NullInt64":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		if t.T() == "NullInt64" {
+			return fmt.Sprintf("%s.Set(%d)", fpath, v), nil
+		}
+		return fmt.Sprintf("%s = %d", fpath, v), nil
+	case "float32", "float64", "NullFloat64":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", err
+		}
+		if t.T() == "NullFloat64" {
+			return fmt.Sprintf("%s.Set(%v)", fpath, v), nil
+		}
+		return fmt.Sprintf("%s = %v", fpath, v), nil
+	case "bool", "NullBool":
+		v, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			return "", err
+		}
+		if t.T() == "NullBool" {
+			return fmt.Sprintf("%s.Set(%t)", fpath, v), nil
+		}
+		return fmt.Sprintf("%s = %t", fpath, v), nil
+	}
+	return "", fmt.Errorf("cannot assign %s", t.T())
+}
+
+// Min returns the condition telling the field is below value. Integer fields
+// compare against value rounded up, as they cannot hold its fraction.
+func (t TypeDetails) Min(path string, value float64) string {
 	switch t.T() {
 	case "int32", "int64":
-		return fmt.Sprintf("%s < %d", path, value)
-	case "NullFloat64", "NullInt64":
-		return fmt.Sprintf("%s.Value() < %d", path, value)
+		return fmt.Sprintf("%s < %s", path, formatLimit(math.Ceil(value)))
+	case "NullInt64":
+		return fmt.Sprintf("%s.Value() < %s", path, formatLimit(math.Ceil(value)))
+	case "NullFloat64":
+		return fmt.Sprintf("%s.Value() < %s", path, formatLimit(value))
 	case "AmountType":
-		return fmt.Sprintf("%s.Value.Value() < %d", path, value)
+		return fmt.Sprintf("%s.Value.Value() < %s", path, formatLimit(value))
 	}
 	log.Fatalf("TypeDetails.Min: unknown %s : %+v", t.T(), t)
 	return ""
 }
 
-func (t TypeDetails) Max(path string, value int) string {
+// Max returns the condition telling the field is above value. Integer fields
+// compare against value rounded down.
+func (t TypeDetails) Max(path string, value float64) string {
 	switch t.T() {
 	case "int32", "int64":
-		return fmt.Sprintf("%s > %d", path, value)
-	case "NullFloat64", "NullInt64":
-		return fmt.Sprintf("%s.Value() > %d", path, value)
+		return fmt.Sprintf("%s > %s", path, formatLimit(math.Floor(value)))
+	case "NullInt64":
+		return fmt.Sprintf("%s.Value() > %s", path, formatLimit(math.Floor(value)))
+	case "NullFloat64":
+		return fmt.Sprintf("%s.Value() > %s", path, formatLimit(value))
 	case "AmountType":
-		return fmt.Sprintf("%s.Value.Value() > %d", path, value)
+		return fmt.Sprintf("%s.Value.Value() > %s", path, formatLimit(value))
 	}
 	log.Fatalf("TypeDetails.Min: unknown %s : %+v", t.T(), t)
 	return ""
 }
+
+// formatLimit writes a Min or Max limit as a Go constant: 1, 0.99.
+func formatLimit(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
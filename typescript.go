@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+)
+
+// writeTypeScript writes TypeScript definitions of the JSON encoding of every
+// generated type and CodeType to -ts: an interface per struct and a union of
+// string literals per CodeType.
+func writeTypeScript() {
+	if *tsFile == "" {
+		return
+	}
+	b := NewBuffer()
+	b.Sprintf("// Code generated by xsdbay. DO NOT EDIT.\n// eBay API version %s\n", *apiVersion)
+
+	var enums []string
+	for name := range Enums {
+		enums = append(enums, name)
+	}
+	sort.Strings(enums)
+	for _, name := range enums {
+		x, ok := FindSimple(name)
+		if !ok {
+			continue
+		}
+		b.Sprintf("\n%sexport type %s = %s;\n", tsComment(description(&x.Annotation), ""), name, tsSimpleType(x))
+	}
+
+	var types []string
+	for name := range Types {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	for _, name := range types {
+		c, ok := FindComplex(name)
+		if !ok {
+			continue
+		}
+		b.Sprintf("\n%sexport interface %s {\n", tsComment(description(&c.Annotation), ""), name)
+		for _, x := range c.GetElements() {
+			details, a, ok := fieldDetails(x)
+			if !ok {
+				continue
+			}
+			tsType, optional := tsField(details)
+			if optional {
+				b.Sprintf("%s  %s?: %s;\n", tsComment(description(a), "  "), ToSnake(x.GetName()), tsType)
+			} else {
+				b.Sprintf("%s  %s: %s;\n", tsComment(description(a), "  "), ToSnake(x.GetName()), tsType)
+			}
+		}
+		b.Sprintf("}\n")
+	}
+
+	if err := ioutil.WriteFile(*tsFile, b.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func tsSimpleType(x *simpleType) string {
+	if values := x.Values(); len(values) > 0 {
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = `"` + v + `"`
+		}
+		return strings.Join(quoted, " | ")
+	}
+	t, _ := TypeDetails{AliasFor: x.GetType(), SimpleType: true}.JSONType()
+	return tsJSONType(t)
+}
+
+// tsField returns the TypeScript type of a struct field and whether it may be
+// left out: pointers, slices and plain values are tagged omitempty, Null*
+// fields are always encoded, as null when unset.
+func tsField(t *TypeDetails) (string, bool) {
+	item := *t
+	item.IsSlice = false
+	tsType := tsValue(&item)
+	if t.IsSlice {
+		if strings.Contains(tsType, " ") {
+			tsType = "(" + tsType + ")"
+		}
+		return tsType + "[]", true
+	}
+	_, nullable := t.JSONType()
+	return tsType, !nullable
+}
+
+func tsValue(t *TypeDetails) string {
+	if _, ok := FindComplex(t.Type.String()); ok && !t.Type.IsXS() {
+		return t.Type.String()
+	}
+	if t.Enum != "" {
+		return t.Enum
+	}
+	if t.SimpleType && !t.Type.IsXS() {
+		if _, ok := FindSimple(t.Type.String()); ok {
+			return t.Type.String()
+		}
+	}
+	jsonType, nullable := t.JSONType()
+	if nullable {
+		return tsJSONType(jsonType) + " | null"
+	}
+	return tsJSONType(jsonType)
+}
+
+func tsJSONType(jsonType string) string {
+	switch jsonType {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "string", "base64":
+		return "string"
+	}
+	return "unknown"
+}
+
+func tsComment(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	return indent + "/** " + strings.Replace(text, "*/", "* /", -1) + " */\n"
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_writeTypeScript(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator")
+	}
+	dir := t.TempDir()
+	generate(t, dir, "-i", "testdata/mini.xsd", "-o", "ebaysvc.go", "-ts", "ebay.d.ts")
+	data, err := ioutil.ReadFile(filepath.Join(dir, "ebay.d.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"export type CurrencyCodeType = \"USD\" | \"EUR\" | \"GBP\";\n",
+		"/** Defines a single new item and lists it. */\nexport interface AddItemRequestType {\n",
+		"  item?: ItemType;\n",
+		"  title: string | null;\n",
+		"  quantity: number | null;\n",
+		"  currency?: CurrencyCodeType;\n",
+		"  picture_url?: (string | null)[];\n",
+		"  errors?: ErrorType[];\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("no %q in\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+// Validation errors returned by the generated Validate() methods.
+var templateValidation = `
+// ValidationType names the rule a field failed.
+type ValidationType string
+
+// ValidationError describes a single field failing validation. Path is the
+// field path below the request or response type (Item.PictureURL[2]), Limit
+// the value the rule allows (if any) and Value the offending value.
+type ValidationError struct {
+	Path  string
+	Type  ValidationType
+	Limit interface{}
+	Value interface{}
+}
+
+func (e ValidationError) Error() string {
+	switch e.Type {
+	case ValidationRequired:
+		return e.Path + " is required"
+	case ValidationRequiredIf:
+		return fmt.Sprintf("%s is required when %v", e.Path, e.Limit)
+	case ValidationReturned:
+		return e.Path + " must be returned"
+	case ValidationMaxOccurs:
+		return fmt.Sprintf("%s has %v elements, at most %v allowed", e.Path, e.Value, e.Limit)
+	case ValidationMinOccurs:
+		return fmt.Sprintf("%s has %v elements, at least %v required", e.Path, e.Value, e.Limit)
+	case ValidationMaxLength:
+		return fmt.Sprintf("%s is longer than %v characters", e.Path, e.Limit)
+	case ValidationMin:
+		return fmt.Sprintf("%s is %v, minimum is %v", e.Path, e.Value, e.Limit)
+	case ValidationMax:
+		return fmt.Sprintf("%s is %v, maximum is %v", e.Path, e.Value, e.Limit)
+	case ValidationAllValuesExcept:
+		return fmt.Sprintf("%s must not be %q", e.Path, e.Value)
+	case ValidationFixed:
+		return fmt.Sprintf("%s must be %q, got %q", e.Path, e.Limit, e.Value)
+	case ValidationOnlyTheseValues, ValidationEnumeration:
+		return fmt.Sprintf("%s must be one of %v, got %q", e.Path, e.Limit, e.Value)
+	}
+	return fmt.Sprintf("%s failed %s validation", e.Path, e.Type)
+}
+
+// ValidationErrors holds every ValidationError found by a Validate() call.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+`
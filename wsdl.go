@@ -1,5 +1,7 @@
 package main
 
+import "encoding/xml"
+
 type definitions struct {
 	Name            string `xml:"name,attr"`
 	TargetNamespace string `xml:"targetNamespace,attr"`
@@ -12,9 +14,15 @@ type definitions struct {
 	WSDL            string `xml:"wsdl,attr"`
 	Mine            string `xml:"mine,attr"`
 	XmlNS           string `xml:"xmlns,attr"`
+	// Namespace declarations, see loadNamespaces.
+	Attrs []xml.Attr `xml:",any,attr"`
 
 	Types types `xml:"types"`
 
+	Message  []message  `xml:"message"`
+	PortType []portType `xml:"portType"`
+	Binding  []binding  `xml:"binding"`
+
 	Service service `xml:"service"`
 }
 
@@ -22,7 +30,152 @@ type types struct {
 	Schema schema `xml:"schema"`
 }
 
+// message is a wsdl:message. Document style operations have a single part
+// naming the element sent in the SOAP body or header.
+type message struct {
+	Name string        `xml:"name,attr"`
+	Part []messagePart `xml:"part"`
+}
+
+type messagePart struct {
+	Name    string `xml:"name,attr"`
+	Element Type   `xml:"element,attr"`
+	Type    Type   `xml:"type,attr"`
+}
+
+type portType struct {
+	Name      string      `xml:"name,attr"`
+	Operation []operation `xml:"operation"`
+}
+
+// operation is an operation of a portType: the messages it takes, returns
+// and the faults it may answer with.
+type operation struct {
+	Name          string             `xml:"name,attr"`
+	Documentation documentation      `xml:"documentation"`
+	Input         operationMessage   `xml:"input"`
+	Output        operationMessage   `xml:"output"`
+	Fault         []operationMessage `xml:"fault"`
+}
+
+type operationMessage struct {
+	Name    string `xml:"name,attr"`
+	Message Type   `xml:"message,attr"`
+}
+
+// binding ties the operations of a portType to SOAP 1.1 or 1.2. Bindings to
+// other protocols have neither SOAP nor SOAP12 set.
+type binding struct {
+	Name      string             `xml:"name,attr"`
+	Type      Type               `xml:"type,attr"`
+	SOAP      *soapBinding       `xml:"http://schemas.xmlsoap.org/wsdl/soap/ binding"`
+	SOAP12    *soapBinding       `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ binding"`
+	Operation []bindingOperation `xml:"operation"`
+}
+
+type soapBinding struct {
+	Style     string `xml:"style,attr"`
+	Transport string `xml:"transport,attr"`
+}
+
+type bindingOperation struct {
+	Name   string         `xml:"name,attr"`
+	SOAP   *soapOperation `xml:"http://schemas.xmlsoap.org/wsdl/soap/ operation"`
+	SOAP12 *soapOperation `xml:"http://schemas.xmlsoap.org/wsdl/soap12/ operation"`
+	Input  bindingMessage `xml:"input"`
+	Output bindingMessage `xml:"output"`
+}
+
+type soapOperation struct {
+	SOAPAction string `xml:"soapAction,attr"`
+	Style      string `xml:"style,attr"`
+}
+
+// bindingMessage lists the SOAP header blocks sent with an operation's
+// input or output.
+type bindingMessage struct {
+	Header []soapHeader `xml:"header"`
+}
+
+type soapHeader struct {
+	Message Type   `xml:"message,attr"`
+	Part    string `xml:"part,attr"`
+	Use     string `xml:"use,attr"`
+}
+
 type service struct {
 	Name          string        `xml:"name,attr"`
 	Documentation documentation `xml:"documentation"`
+	Port          []port        `xml:"port"`
+}
+
+type port struct {
+	Name    string  `xml:"name,attr"`
+	Binding Type    `xml:"binding,attr"`
+	Address address `xml:"address"`
+}
+
+type address struct {
+	Location string `xml:"location,attr"`
+}
+
+// Version returns the SOAP version of the binding, 0 if it is not a SOAP
+// binding.
+func (b binding) Version() int {
+	switch {
+	case b.SOAP12 != nil:
+		return 12
+	case b.SOAP != nil:
+		return 11
+	}
+	return 0
+}
+
+// SOAPAction returns the SOAPAction of the operation.
+func (o bindingOperation) SOAPAction() string {
+	if o.SOAP12 != nil {
+		return o.SOAP12.SOAPAction
+	}
+	if o.SOAP != nil {
+		return o.SOAP.SOAPAction
+	}
+	return ""
+}
+
+func (d *definitions) FindMessage(name Type) (*message, bool) {
+	for i := range d.Message {
+		if d.Message[i].Name == name.String() {
+			return &d.Message[i], true
+		}
+	}
+	return nil, false
+}
+
+func (d *definitions) FindPortType(name Type) (*portType, bool) {
+	for i := range d.PortType {
+		if d.PortType[i].Name == name.String() {
+			return &d.PortType[i], true
+		}
+	}
+	return nil, false
+}
+
+func (d *definitions) FindBinding(name Type) (*binding, bool) {
+	for i := range d.Binding {
+		if d.Binding[i].Name == name.String() {
+			return &d.Binding[i], true
+		}
+	}
+	return nil, false
+}
+
+// FindPart returns the part of the message, or its first part if name is
+// empty.
+func (m message) FindPart(name string) (*messagePart, bool) {
+	for i := range m.Part {
+		if m.Part[i].Name == name || (name == "" && i == 0) {
+			return &m.Part[i], true
+		}
+	}
+	return nil, false
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/xml"
 	"errors"
 	"log"
 	"regexp"
@@ -9,20 +10,79 @@ import (
 )
 
 var TypeMap map[string]string = map[string]string{
-	"other":        "string",
-	"token":        "string",
-	"dateTime":     "string",
-	"duration":     "string",
-	"time":         "string",
-	"anyURI":       "string",
-	"base64Binary": "[]byte",
-	"string":       "string",
-	"boolean":      "bool",
-	"float":        "float64", //32
-	"double":       "float64",
-	"decimal":      "float64",
-	"int":          "int64", //32
-	"long":         "int64",
+	"other":              "string",
+	"token":              "string",
+	"dateTime":           "string",
+	"duration":           "string",
+	"time":               "string",
+	"anyURI":             "string",
+	"base64Binary":       "[]byte",
+	"string":             "string",
+	"boolean":            "bool",
+	"float":              "float64", //32
+	"double":             "float64",
+	"decimal":            "float64",
+	"int":                "int64", //32
+	"long":               "int64",
+	"anyType":            "string",
+	"anySimpleType":      "string",
+	"normalizedString":   "string",
+	"language":           "string",
+	"Name":               "string",
+	"NCName":             "string",
+	"NMTOKEN":            "string",
+	"NMTOKENS":           "string",
+	"ID":                 "string",
+	"IDREF":              "string",
+	"IDREFS":             "string",
+	"ENTITY":             "string",
+	"ENTITIES":           "string",
+	"QName":              "string",
+	"NOTATION":           "string",
+	"hexBinary":          "string",
+	"date":               "string",
+	"gYear":              "string",
+	"gYearMonth":         "string",
+	"gMonth":             "string",
+	"gMonthDay":          "string",
+	"gDay":               "string",
+	"integer":            "int64",
+	"nonNegativeInteger": "int64",
+	"positiveInteger":    "int64",
+	"nonPositiveInteger": "int64",
+	"negativeInteger":    "int64",
+	"short":              "int64", //16
+	"byte":               "int64", //8
+	"unsignedLong":       "int64",
+	"unsignedInt":        "int64",
+	"unsignedShort":      "int64",
+	"unsignedByte":       "int64",
+}
+
+// xsdNamespace is the namespace of the XML Schema types in TypeMap.
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// xsPrefixes holds the prefixes bound to xsdNamespace by the input file.
+// xsDefault is set when it is the default namespace, so unprefixed TypeMap
+// names are XML Schema types too.
+var (
+	xsPrefixes = map[string]bool{"xs": true}
+	xsDefault  bool
+)
+
+// loadNamespaces records the prefixes the attributes bind to xsdNamespace.
+func loadNamespaces(attrs []xml.Attr) {
+	for _, a := range attrs {
+		if a.Value != xsdNamespace {
+			continue
+		}
+		switch {
+		case a.Name.Space == "xmlns":
+			xsPrefixes[a.Name.Local] = true
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			xsDefault = true
+		}
+	}
 }
 
 var SubstituteMap map[string]string = map[string]string{
@@ -77,17 +137,15 @@ func (e Type) IsBasic() bool {
 }
 
 func (e Type) IsNS() bool {
-	if strings.Contains(string(e), ":") {
-		return strings.SplitAfterN(string(e), ":", 2)[0] != "xs:"
-	}
-	return e[:3] == "ns:"
+	return e != "" && !e.IsXS()
 }
 
 func (e Type) IsXS() bool {
-	if len(string(e)) < 4 {
-		panic(string(e))
+	if i := strings.Index(string(e), ":"); i >= 0 {
+		return xsPrefixes[string(e[:i])]
 	}
-	return e[:3] == "xs:"
+	_, builtin := TypeMap[string(e)]
+	return xsDefault && builtin
 }
 
 func (e Type) IsSimpleType() bool {
@@ -141,6 +199,8 @@ type schema struct {
 	Xmlns                string `xml:"xmlns,attr"`
 	Version              string `xml:"version,attr"`
 	XmlLang              string `xml:"lang,attr"`
+	// Namespace declarations, see loadNamespaces.
+	Attrs []xml.Attr `xml:",any,attr"`
 
 	//Include    []includeMany `xml:"include"`
 	//Import     []importMany  `xml:"import"`
@@ -168,8 +228,8 @@ type element struct {
 	MinOccurs string `xml:"minOccurs,attr"`
 	Name      string `xml:"name,attr"`
 	Nillable  bool   `xml:"nillable,attr"`
-	// Ref               string `xml:"ref,attr"`
-	Type Type `xml:"type,attr"`
+	Ref       string `xml:"ref,attr"`
+	Type      Type   `xml:"type,attr"`
 
 	Annotation  *annotation  `xml:"annotation"`
 	SimpleType  *simpleType  `xml:"simpleType"`
@@ -196,6 +256,24 @@ func (e element) SliceLen() (int, bool) {
 	return mo, mo > 1
 }
 
+// MinLen returns the minimum number of elements of a slice field for callName:
+// the largest of the XSD minOccurs and the appinfo MinOccurs.
+// fromXSD reports whether the minimum comes from the XSD and so also applies
+// to an empty slice. A missing minOccurs counts as 0.
+func (e element) MinLen(callName string) (min int, fromXSD bool) {
+	if e.MinOccurs != "" {
+		mo, err := strconv.Atoi(e.MinOccurs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		min, fromXSD = mo, mo > 0
+	}
+	if mo, ok := appInfoExt.MinOccurs(e.Annotation, callName); ok && mo > min {
+		min = mo
+	}
+	return
+}
+
 // https://msdn.microsoft.com/en-us/library/ms256067(v=vs.110).aspx
 //  Number of occurrences: Unlimited within schema; one time within element.
 type complexType struct {
@@ -214,8 +292,11 @@ type complexType struct {
 	// The complex type contains only elements or no element content (empty).
 	ComplexContent *complexContent `xml:"complexContent"`
 	//group
-	//all
-	//choice
+
+	// The complex type contains the elements of the group in any order, or
+	// one of them. Both are moved to Sequence by schema.normalise.
+	All    *sequence `xml:"all"`
+	Choice *sequence `xml:"choice"`
 
 	// The complex type contains the elements defined in the specified sequence.
 	Sequence  *sequence   `xml:"sequence"`
@@ -263,9 +344,9 @@ type extensioncomplexContent struct {
 	Attribute  []attribute `xml:"attribute"`
 	//attributeGroup
 	//anyAttribute
-	//choice
-	//all
-	Sequence sequence `xml:"sequence"`
+	Choice   *sequence `xml:"choice"`
+	All      *sequence `xml:"all"`
+	Sequence sequence  `xml:"sequence"`
 	//group
 }
 
@@ -433,13 +514,27 @@ func (e attribute) NeedsValidation(callName string) (ValidationContainer, bool)
 		return list, false
 	}
 	a := e.Annotation
-	if a.RequiredFor(callName) || e.Use == "required" {
+	if e.Fixed != "" {
+		list.New(ValTypFixed, e.Fixed)
+	}
+	if strings.HasSuffix(callName, "Response") {
+		if appInfoExt.ReturnedFor(a, strings.TrimSuffix(callName, "Response")) == "Always" || e.Use == "required" {
+			list.New(ValTypReturned, nil)
+		}
+		if details := e.TypeDetails(); details.Enum != "" {
+			list.New(ValTypEnumeration, details.Enum)
+		}
+		return list, list.Len() > 0
+	}
+
+	if appInfoExt.RequiredFor(a, callName) || e.Use == "required" {
 		list.New(ValTypRequired, nil)
 	}
 
-	if nlist, ok := a.AppInfo.ValidationRules(callName); ok {
+	if nlist, ok := appInfoExt.ValidationRules(a, callName); ok {
 		list = append(list, nlist...)
 	}
+	list.Enumeration(e.TypeDetails().Enum)
 
 	return list, list.Len() > 0
 }
@@ -447,6 +542,14 @@ func (e attribute) NeedsValidation(callName string) (ValidationContainer, bool)
 type ValidationRule struct {
 	Type  ValidationType
 	Value interface{}
+	// IfSet limits the check to fields holding a value.
+	IfSet bool
+}
+
+// SkipsUnset reports whether the check needs a guard for unset fields. Only
+// Min, Max and MinOccurs checks would fail on an unset field.
+func (v ValidationRule) SkipsUnset() bool {
+	return v.IfSet && (v.Type == ValTypMin || v.Type == ValTypMax || v.Type == ValTypMinOccurs)
 }
 
 func (v ValidationRule) ValueInt() (int, error) {
@@ -484,12 +587,30 @@ parse:
 	return int(flt), nil
 }
 
+// ValueFloat returns the value of a Min or Max rule, which may have a
+// fraction.
+func (v ValidationRule) ValueFloat() (float64, error) {
+	return strconv.ParseFloat(strings.Split(v.Value.(string), " ")[0], 64)
+}
+
 type ValidationContainer []ValidationRule
 
 func (x *ValidationContainer) New(validation ValidationType, value interface{}) {
 	*x = append(*x, ValidationRule{Type: validation, Value: value})
 }
 
+// Enumeration adds the membership check of an enumerated type unless the
+// call already narrows the field down with OnlyTheseValues.
+func (x *ValidationContainer) Enumeration(enum string) {
+	if enum == "" {
+		return
+	}
+	if _, ok := x.Includes(ValTypOnlyTheseValues); ok {
+		return
+	}
+	x.New(ValTypEnumeration, enum)
+}
+
 func (x ValidationContainer) Len() int {
 	return len(x)
 }
@@ -568,46 +689,124 @@ func (a appInfo) ValidationRules(callName string) (ValidationContainer, bool) {
 
 func (e element) NeedsValidation(callName string) (ValidationContainer, bool) {
 	list := ValidationContainer{}
-	if strings.HasSuffix(callName, "Response") {
-		return list, false
-	}
 	if e.Annotation == nil {
 		return list, false
 	}
 	a := e.Annotation
 
-	if a.RequiredFor(callName) {
-		list.New(ValTypRequired, nil)
-	} else {
+	if strings.HasSuffix(callName, "Response") {
+		if appInfoExt.ReturnedFor(a, strings.TrimSuffix(callName, "Response")) == "Always" {
+			list.New(ValTypReturned, nil)
+		}
+		if details := e.TypeDetails(); details.Enum != "" {
+			list.New(ValTypEnumeration, details.Enum)
+		}
+		// Fields returned only conditionally are still walked for their
+		// own always returned fields.
+		if list.Len() == 0 && e.GetType().IsComplexType() {
+			return list, e.GetRelated().DeepValidator(callName, "")
+		}
 		return list, list.Len() > 0
 	}
 
-	if nlist, ok := a.AppInfo.ValidationRules(callName); ok {
-		list = append(list, nlist...)
+	enum := e.TypeDetails().Enum
+	required := appInfoExt.RequiredFor(a, callName)
+	if required {
+		list.New(ValTypRequired, nil)
+	}
+	if min, fromXSD := e.MinLen(callName); min > 0 && e.TypeDetails().IsSlice && !(required && min == 1) {
+		// eBay minimums on optional fields apply only once the field is used.
+		list = append(list, ValidationRule{Type: ValTypMinOccurs, Value: min, IfSet: !required && !fromXSD})
+	}
+	if !required && e.HasConditions(callName) {
+		// Checked by complexType.Validator, which knows the other fields.
+		list.New(ValTypRequiredIf, nil)
+	}
+	// Optional enumerated fields are only checked against their values.
+	onlyEnum := !required && list.Len() == 0
+	if onlyEnum && enum == "" {
+		// Optional fields are still walked for their enumerated fields.
+		if e.GetType().IsComplexType() {
+			return list, e.GetRelated().DeepValidator(callName, "")
+		}
+		return list, false
 	}
 
+	if nlist, ok := appInfoExt.ValidationRules(a, callName); ok {
+		for _, rule := range nlist {
+			if onlyEnum && rule.Type != ValTypAllValuesExcept && rule.Type != ValTypOnlyTheseValues {
+				continue
+			}
+			// Limits of optional fields apply only once the field is used.
+			rule.IfSet = !required
+			list = append(list, rule)
+		}
+	}
+	list.Enumeration(enum)
+
 	return list, list.Len() > 0
 }
 
+// RequiredFor reports whether the request of callName requires the field,
+// see RequiredInputFor.
 func (a annotation) RequiredFor(callName string) bool {
+	return a.RequiredInputFor(callName) == "Yes"
+}
+
+// RequiredInputFor returns the RequiredInput of the field in the request of
+// callName: "Yes", "No", "Conditionally" or "" if the call does not take it.
+func (a annotation) RequiredInputFor(callName string) string {
 	for _, ci := range a.AppInfo.CallInfo {
 		if ci.AllCallsExcept != "" {
 			excepts := strings.Split(strings.Replace(ci.AllCallsExcept, " ", "", -1), ",")
 			if contains(excepts, callName) {
-				return false
+				continue
 			}
-			return true
+			return ci.RequiredInput
 		}
-		if ci.AllCalls != nil {
-			return ci.RequiredInput == "Yes"
+		if ci.AllCalls != nil || contains(ci.CallName, callName) {
+			return ci.RequiredInput
 		}
-		for _, c := range ci.CallName {
-			if c == callName {
-				return ci.RequiredInput == "Yes"
+	}
+	return ""
+}
+
+// DefaultFor returns the documented default of the field in the request of
+// callName. A default given for the call wins over the general one, which
+// only applies to calls taking the field as input.
+func (a annotation) DefaultFor(callName string) (string, bool) {
+	for _, ci := range a.AppInfo.CallInfo {
+		if contains(ci.CallName, callName) {
+			if x, ok := ci.Default(); ok {
+				return x, true
 			}
 		}
 	}
-	return false
+	if !a.IncludedIn(callName, true) {
+		return "", false
+	}
+	return a.AppInfo.Default()
+}
+
+// ReturnedFor returns how the field is returned in the response of callName:
+// "Always", "Conditionally" or "" if it is not returned.
+func (a annotation) ReturnedFor(callName string) string {
+	for _, ci := range a.AppInfo.CallInfo {
+		if ci.Returned == "" {
+			continue
+		}
+		if ci.AllCallsExcept != "" {
+			excepts := strings.Split(strings.Replace(ci.AllCallsExcept, " ", "", -1), ",")
+			if contains(excepts, callName) {
+				continue
+			}
+			return ci.Returned
+		}
+		if ci.AllCalls != nil || contains(ci.CallName, callName) {
+			return ci.Returned
+		}
+	}
+	return ""
 }
 
 func (a annotation) RequiredInput() bool {
@@ -664,6 +863,7 @@ type appInfo struct {
 	//Source string `xml:"source,attr"`
 
 	EbAppInfo
+	SOACallInfo []soaCallInfo `xml:"callInfo"`
 }
 
 // https://msdn.microsoft.com/en-us/library/ms256112(v=vs.110).aspx
@@ -685,8 +885,68 @@ type sequence struct {
 
 	Annotation annotation `xml:"annotation"`
 	//any
-	//choice
+	// Element holds the elements of the nested choice and sequence groups
+	// too, see UnmarshalXML.
 	Element []element `xml:"element"`
 	//group
-	//Sequence []sequence `xml:"sequence"`
+}
+
+// UnmarshalXML reads a sequence, choice or all group. The elements of nested
+// groups are added to Element in document order.
+func (s *sequence) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			s.ID = a.Value
+		case "maxOccurs":
+			s.MaxOccurs = a.Value
+		case "minOccurs":
+			s.MinOccurs = a.Value
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "annotation":
+				err = d.DecodeElement(&s.Annotation, &t)
+			case "element":
+				var e element
+				err = d.DecodeElement(&e, &t)
+				s.Element = append(s.Element, e)
+			case "sequence", "choice", "all":
+				var g sequence
+				err = d.DecodeElement(&g, &t)
+				s.Element = append(s.Element, g.Members(t.Name.Local == "choice")...)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Members returns the elements of a group nested in another one. Elements of
+// a choice, or of an optional group, are optional; those of a repeated group
+// are repeated.
+func (s sequence) Members(choice bool) []element {
+	members := make([]element, len(s.Element))
+	for i, e := range s.Element {
+		if choice || s.MinOccurs == "0" {
+			e.MinOccurs = "0"
+		}
+		if s.MaxOccurs != "" && s.MaxOccurs != "1" && e.MaxOccurs == "" {
+			e.MaxOccurs = s.MaxOccurs
+		}
+		members[i] = e
+	}
+	return members
 }
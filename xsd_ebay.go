@@ -1,10 +1,13 @@
 package main
 
+import "strings"
+
 // type EbValidator interface {
 // 	NoCall() bool
 // 	AllValuesExcept() (string, bool)
 // 	OnlyTheseValues() (string, bool)
 // 	MaxLength() (string, bool)
+// 	MinOccurs() (int, bool)
 // 	MaxOccurs() (int, bool)
 // 	Min() (string, bool)
 // 	Max() (string, bool)
@@ -12,9 +15,9 @@ package main
 // }
 
 type EbAppInfo struct {
-	MaxDepth  int
-	MinOccurs int
+	MaxDepth int
 	//PresentDetails
+	EbMinOccurs
 	EbMaxOccurs
 	EbAllValuesExcept
 	EbOnlyTheseValues
@@ -41,6 +44,7 @@ type EbAppInfo struct {
 }
 
 type ebCallInfo struct {
+	EbMinOccurs
 	EbMaxOccurs
 	EbAllValuesExcept
 	EbOnlyTheseValues
@@ -156,6 +160,21 @@ func (n EbMaxOccurs) MaxOccurs() (int, bool) {
 	return 0, false
 }
 
+type EbMinOccurs struct {
+	LowerCaseMinOccurs *int `xml:"minOccurs"`
+	UpperCaseMinOccurs *int `xml:"MinOccurs"`
+}
+
+func (n EbMinOccurs) MinOccurs() (int, bool) {
+	if n.LowerCaseMinOccurs != nil {
+		return *n.LowerCaseMinOccurs, true
+	}
+	if n.UpperCaseMinOccurs != nil {
+		return *n.UpperCaseMinOccurs, true
+	}
+	return 0, false
+}
+
 type EbMin struct {
 	LowerMin *string `xml:"min"`
 	UpperMin *string `xml:"Min"`
@@ -200,3 +219,94 @@ func (n EbDefault) Default() (string, bool) {
 	}
 	return "", false
 }
+
+// ebayAppInfo reads the eBay dialect of appinfo: CallInfo lists the calls
+// taking or returning a field, with their limits and defaults.
+type ebayAppInfo struct{}
+
+// Calls returns the calls of the schema, one for each <Call>Request element.
+func (ebayAppInfo) Calls(s *schema) (calls []string) {
+	for _, e := range s.Element {
+		if strings.HasSuffix(e.Name, "Request") {
+			calls = append(calls, strings.TrimSuffix(e.Name, "Request"))
+		}
+	}
+	return
+}
+
+// ebayAnnotation stands in for a missing annotation, which is treated as
+// one without CallInfo.
+func ebayAnnotation(a *annotation) *annotation {
+	if a == nil {
+		return &annotation{}
+	}
+	return a
+}
+
+func (ebayAppInfo) Skip(a *annotation) bool {
+	return ebayAnnotation(a).Skip()
+}
+
+func (ebayAppInfo) IncludedIn(a *annotation, callName string, request bool) bool {
+	return ebayAnnotation(a).IncludedIn(callName, request)
+}
+
+func (ebayAppInfo) RequiredFor(a *annotation, callName string) bool {
+	return ebayAnnotation(a).RequiredFor(callName)
+}
+
+func (ebayAppInfo) RequiredInputFor(a *annotation, callName string) string {
+	return ebayAnnotation(a).RequiredInputFor(callName)
+}
+
+func (ebayAppInfo) ReturnedFor(a *annotation, callName string) string {
+	return ebayAnnotation(a).ReturnedFor(callName)
+}
+
+// RequiredIf reads the conditions from the Details and Context text of
+// fields whose RequiredInput is "Conditionally".
+func (ebayAppInfo) RequiredIf(a *annotation, callName, parent string) (list []Condition) {
+	for _, ci := range ebayAnnotation(a).AppInfo.CallInfo {
+		if ci.RequiredInput != "Conditionally" || !(ci.AllCalls != nil || contains(ci.CallName, callName)) {
+			continue
+		}
+		for _, text := range []string{ci.Details, ci.Context} {
+			if m := requiredIfText.FindStringSubmatch(text); len(m) == 3 {
+				list = append(list, Condition{
+					Field:  joinPath(parent, UpperFirstLetter(m[1])),
+					Values: conditionValues(m[2]),
+				})
+				break
+			}
+		}
+	}
+	return
+}
+
+func (ebayAppInfo) DefaultFor(a *annotation, callName string) (string, bool) {
+	return ebayAnnotation(a).DefaultFor(callName)
+}
+
+// MinOccurs returns the largest of the general MinOccurs and the one given
+// for callName.
+func (ebayAppInfo) MinOccurs(a *annotation, callName string) (min int, ok bool) {
+	info := ebayAnnotation(a).AppInfo
+	min, ok = info.MinOccurs()
+	for _, ci := range info.CallInfo {
+		if !contains(ci.CallName, callName) {
+			continue
+		}
+		if mo, found := ci.MinOccurs(); found && (!ok || mo > min) {
+			min, ok = mo, true
+		}
+	}
+	return
+}
+
+func (ebayAppInfo) ValidationRules(a *annotation, callName string) (ValidationContainer, bool) {
+	return ebayAnnotation(a).AppInfo.ValidationRules(callName)
+}
+
+func (ebayAppInfo) ListBasedOn(a *annotation) (string, bool) {
+	return ebayAnnotation(a).AppInfo.ListBasedOn()
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+)
+
+// normaliser gives anonymous types a name, moves them to the top level of
+// the schema and resolves references, so generation only deals with named
+// types. names holds the type names in use.
+type normaliser struct {
+	s     *schema
+	names map[string]bool
+}
+
+// normalise prepares a schema for generation, see normaliser. attrs are the
+// namespace declarations of the file's root element.
+func (s *schema) normalise(attrs []xml.Attr) {
+	loadNamespaces(attrs)
+	if s.Xmlns == xsdNamespace {
+		xsDefault = true
+	}
+
+	n := &normaliser{s: s, names: map[string]bool{}}
+	for _, c := range s.ComplexType {
+		n.names[c.Name] = true
+	}
+	for _, x := range s.SimpleType {
+		n.names[x.Name] = true
+	}
+
+	for i := range s.SimpleType {
+		n.simpleType(&s.SimpleType[i])
+	}
+	s.Attribute = n.attributes("", s.Attribute)
+	for i := range s.Element {
+		n.element(&s.Element[i], "")
+	}
+	// Types hoisted from the ones walked are appended and walked in turn.
+	for i := 0; i < len(s.ComplexType); i++ {
+		n.complexType(i)
+	}
+}
+
+// name returns an unused type name for an anonymous type.
+func (n *normaliser) name(name string) string {
+	name = UpperFirstLetter(name)
+	unique := name
+	for i := 2; n.names[unique] || Type(unique).IsXS(); i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	n.names[unique] = true
+	return unique
+}
+
+func (n *normaliser) complexType(i int) {
+	c := n.s.ComplexType[i]
+
+	// A restriction restates the content of the type.
+	if cc := c.ComplexContent; cc != nil && cc.Restriction != nil {
+		c.Sequence = &cc.Restriction.Sequence
+		c.Attribute = append(c.Attribute, cc.Restriction.Attribute...)
+		c.ComplexContent = nil
+	}
+	c.Sequence = n.group(c.Name, c.Sequence, c.Choice, c.All)
+	c.Choice, c.All = nil, nil
+
+	if cc := c.ComplexContent; cc != nil && cc.Extension != nil {
+		x := cc.Extension
+		if seq := n.group(c.Name, &x.Sequence, x.Choice, x.All); seq != nil {
+			x.Sequence = *seq
+		}
+		x.Choice, x.All = nil, nil
+		x.Attribute = n.attributes(c.Name, x.Attribute)
+	}
+	if sc := c.SimpleContent; sc != nil {
+		if sc.Extension == nil && sc.Restriction != nil {
+			sc.Extension = n.simpleContent(sc.Restriction)
+		}
+		if sc.Extension != nil {
+			sc.Extension.Attribute = n.attributes(c.Name, sc.Extension.Attribute)
+		}
+	}
+	c.Attribute = n.attributes(c.Name, c.Attribute)
+
+	n.s.ComplexType[i] = c
+}
+
+// group merges the content groups of a type, at most one of which is set by
+// a valid schema, into a sequence.
+func (n *normaliser) group(parent string, seq, choice, all *sequence) *sequence {
+	var r *sequence
+	switch {
+	case seq != nil && len(seq.Element) > 0:
+		r = seq
+	case choice != nil:
+		r = &sequence{ID: choice.ID, Annotation: choice.Annotation, Element: choice.Members(true)}
+	case all != nil:
+		r = all
+	default:
+		return nil
+	}
+	for i := range r.Element {
+		n.element(&r.Element[i], parent)
+	}
+	return r
+}
+
+// simpleContent returns the extension a simple content restriction amounts
+// to: the value and attributes of the restricted type.
+func (n *normaliser) simpleContent(r *restrictionSimpleContent) *extensionSimpleContent {
+	if base, ok := FindComplex(Type(r.Base).String()); ok && base.SimpleContent != nil && base.SimpleContent.Extension != nil {
+		x := *base.SimpleContent.Extension
+		return &x
+	}
+	return &extensionSimpleContent{Base: Type(r.Base)}
+}
+
+func (n *normaliser) element(e *element, parent string) {
+	if e.Ref != "" {
+		n.elementRef(e)
+	}
+	switch {
+	case e.Type != "":
+	case e.ComplexType != nil:
+		x := *e.ComplexType
+		x.Name = n.name(parent + UpperFirstLetter(e.Name))
+		n.s.ComplexType = append(n.s.ComplexType, x)
+		e.Type = Type(x.Name)
+	case e.SimpleType != nil:
+		x := *e.SimpleType
+		x.Name = n.name(parent + UpperFirstLetter(e.Name))
+		n.simpleType(&x)
+		n.s.SimpleType = append(n.s.SimpleType, x)
+		e.Type = Type(x.Name)
+	default:
+		e.Type = "xs:anyType"
+	}
+	e.ComplexType, e.SimpleType = nil, nil
+}
+
+// elementRef replaces a reference by the top-level element it names, keeping
+// the occurrence limits of the reference.
+func (n *normaliser) elementRef(e *element) {
+	name := Type(e.Ref).String()
+	e.Ref = ""
+	for _, r := range n.s.Element {
+		if r.Name != name {
+			continue
+		}
+		e.Name, e.Type = r.Name, r.Type
+		if e.Annotation == nil {
+			e.Annotation = r.Annotation
+		}
+		return
+	}
+	log.Printf("Could not find referenced element %s, using xs:anyType", name)
+	e.Name = name
+}
+
+func (n *normaliser) attributes(parent string, list []attribute) []attribute {
+	for i := range list {
+		a := &list[i]
+		if a.Ref != "" {
+			n.attributeRef(a)
+		}
+		switch {
+		case a.Type != "":
+		case len(a.SimpleType) > 0:
+			x := a.SimpleType[0]
+			x.Name = n.name(parent + UpperFirstLetter(a.Name))
+			n.simpleType(&x)
+			n.s.SimpleType = append(n.s.SimpleType, x)
+			a.Type = Type(x.Name)
+		default:
+			a.Type = "xs:anySimpleType"
+		}
+		a.SimpleType = nil
+	}
+	return list
+}
+
+// attributeRef replaces a reference by the top-level attribute it names.
+// References to attributes of other schemas, like xml:lang, are strings.
+func (n *normaliser) attributeRef(a *attribute) {
+	name := Type(a.Ref).String()
+	a.Ref = ""
+	for _, r := range n.s.Attribute {
+		if r.Name != name {
+			continue
+		}
+		a.Name, a.Type = r.Name, r.Type
+		if a.Annotation == nil {
+			a.Annotation = r.Annotation
+		}
+		return
+	}
+	a.Name, a.Type = name, "xs:string"
+}
+
+// simpleType gives lists, unions and restrictions of anonymous types a
+// string base.
+func (n *normaliser) simpleType(x *simpleType) {
+	if x.Restriction == nil {
+		x.Restriction = &restrictionSimpleType{Base: "xs:string"}
+	}
+	if x.Restriction.Base == "" {
+		x.Restriction.Base = "xs:string"
+		if r := x.Restriction.SimpleType.Restriction; r != nil && r.Base != "" {
+			x.Restriction.Base = r.Base
+		}
+	}
+}
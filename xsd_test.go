@@ -2,7 +2,10 @@ package main
 
 import (
 	"encoding/xml"
+	"fmt"
 	"io/ioutil"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -52,3 +55,327 @@ func Test_annotation_RequiredInput(t *testing.T) {
 		})
 	}
 }
+
+func Test_annotation_ReturnedFor(t *testing.T) {
+	var a annotation
+	err := xml.Unmarshal([]byte(`<annotation><appinfo>
+		<CallInfo><CallName>AddItem</CallName><RequiredInput>Yes</RequiredInput></CallInfo>
+		<CallInfo><CallName>AddItem</CallName><CallName>GetItem</CallName><Returned>Always</Returned></CallInfo>
+		<CallInfo><AllCallsExcept>GetOrders, GetItem</AllCallsExcept><Returned>Conditionally</Returned></CallInfo>
+	</appinfo></annotation>`), &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		callName string
+		want     string
+	}{
+		{"AddItem", "Always"},
+		{"GetItem", "Always"},
+		{"GetOrders", ""},
+		{"GetSellerList", "Conditionally"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.callName, func(t *testing.T) {
+			if got := a.ReturnedFor(tt.callName); got != tt.want {
+				t.Errorf("annotation.ReturnedFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// RequiredFor agrees with RequiredInputFor, also for calls an
+// AllCallsExcept leaves out or takes as optional.
+func Test_annotation_RequiredFor(t *testing.T) {
+	var a annotation
+	err := xml.Unmarshal([]byte(`<annotation><appinfo>
+		<CallInfo><AllCallsExcept>AddItem, GetItem</AllCallsExcept><RequiredInput>No</RequiredInput></CallInfo>
+		<CallInfo><CallName>AddItem</CallName><RequiredInput>Yes</RequiredInput></CallInfo>
+		<CallInfo><CallName>GetItem</CallName><Returned>Always</Returned></CallInfo>
+	</appinfo></annotation>`), &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		callName      string
+		requiredInput string
+		required      bool
+	}{
+		{"AddItem", "Yes", true},
+		{"GetItem", "", false},
+		{"ReviseItem", "No", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.callName, func(t *testing.T) {
+			if got := a.RequiredInputFor(tt.callName); got != tt.requiredInput {
+				t.Errorf("annotation.RequiredInputFor() = %q, want %q", got, tt.requiredInput)
+			}
+			if got := a.RequiredFor(tt.callName); got != tt.required {
+				t.Errorf("annotation.RequiredFor() = %v, want %v", got, tt.required)
+			}
+		})
+	}
+}
+
+func Test_soaAppInfo_RequiredFor(t *testing.T) {
+	var keywords, categoryID annotation
+	err := xml.Unmarshal([]byte(`<annotation><appinfo>
+		<callInfo><allCalls/><requiredInput>Yes</requiredInput></callInfo>
+	</appinfo></annotation>`), &keywords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = xml.Unmarshal([]byte(`<annotation><appinfo>
+		<callInfo><callName>findItemsByCategory</callName><requiredInput>Yes</requiredInput></callInfo>
+		<callInfo><callName>findItemsAdvanced</callName><requiredInput>Conditionally</requiredInput></callInfo>
+	</appinfo></annotation>`), &categoryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		a             *annotation
+		callName      string
+		requiredInput string
+	}{
+		{"allCalls", &keywords, "FindItemsByKeywords", "Yes"},
+		{"callName", &categoryID, "FindItemsByCategory", "Yes"},
+		{"conditionally", &categoryID, "FindItemsAdvanced", "Conditionally"},
+		{"other call", &categoryID, "FindItemsByKeywords", ""},
+		{"no annotation", nil, "FindItemsByKeywords", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (soaAppInfo{}).RequiredInputFor(tt.a, tt.callName); got != tt.requiredInput {
+				t.Errorf("soaAppInfo.RequiredInputFor() = %q, want %q", got, tt.requiredInput)
+			}
+			if got := (soaAppInfo{}).RequiredFor(tt.a, tt.callName); got != (tt.requiredInput == "Yes") {
+				t.Errorf("soaAppInfo.RequiredFor() = %v, want %v", got, tt.requiredInput == "Yes")
+			}
+		})
+	}
+}
+
+// loadSchema replaces the schema with src, normalised like readInputFile
+// does, exports calls and clears everything generated from the previous one.
+func loadSchema(t *testing.T, src string, calls ...string) {
+	t.Helper()
+	xsdSc = schema{}
+	if err := xml.Unmarshal([]byte(src), &xsdSc); err != nil {
+		t.Fatal(err)
+	}
+	fileType = extXSD
+	xsdSc.normalise(xsdSc.Attrs)
+	exportedElements = calls
+	for _, generated := range []map[string]buffer{Types, Calls, Enums, Funcs, Validator, Defaults} {
+		for k := range generated {
+			delete(generated, k)
+		}
+	}
+	deepValidated = map[string]bool{}
+	deepDefaulted = map[string]bool{}
+	rootElements = map[string]string{}
+}
+
+// requestSchema has an AddItem request with required attributes of plain Go
+// types and types recursing through an optional field.
+const requestSchema = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ns="urn:ebay:apis:eBLBaseComponents" targetNamespace="urn:ebay:apis:eBLBaseComponents" elementFormDefault="qualified">
+<xs:element name="AddItemRequest" type="ns:AddItemRequestType"/>
+<xs:element name="AddItemResponse" type="ns:AddItemResponseType"/>
+<xs:complexType name="AddItemRequestType"><xs:sequence>
+ <xs:element name="Item" type="ns:ItemType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><CallName>AddItem</CallName><RequiredInput>Yes</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="AddItemResponseType"><xs:sequence/></xs:complexType>
+<xs:complexType name="ItemType"><xs:sequence>
+ <xs:element name="StartPrice" type="ns:AmountType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><CallName>AddItem</CallName><RequiredInput>Yes</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="Variation" type="ns:VariationType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><CallName>AddItem</CallName><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="VariationType"><xs:sequence>
+ <xs:element name="Item" type="ns:ItemType" minOccurs="0"><xs:annotation><xs:appinfo><CallInfo><CallName>AddItem</CallName><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:complexType name="AmountType"><xs:simpleContent><xs:extension base="xs:double">
+ <xs:attribute name="currencyID" type="xs:string" use="required"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>Yes</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:attribute>
+ <xs:attribute name="rate" type="xs:double" use="required"><xs:annotation><xs:appinfo><CallInfo><AllCalls/><RequiredInput>Yes</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:attribute>
+</xs:extension></xs:simpleContent></xs:complexType>
+</xs:schema>`
+
+// The checks response validation needed change request validation too:
+// required attributes are plain Go types, float fields can be required and
+// recursive types are walked once per branch.
+func Test_complexType_Validator_request(t *testing.T) {
+	loadSchema(t, requestSchema, "AddItem")
+	FromRequest("AddItem")
+	got := Validator["AddItem"].String()
+
+	for _, want := range []string{
+		`if x.Item.StartPrice.CurrencyID == "" {`,
+		`if x.Item.StartPrice.Rate == 0 {`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Validator() has no %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "x.Item.Variation.Item.Variation") {
+		t.Errorf("Validator() walked ItemType twice in one branch:\n%s", got)
+	}
+}
+
+// Optional enumerated fields are checked against their values, narrowed by
+// the call, and not against the other limits of their appinfo.
+func Test_element_NeedsValidation_enum(t *testing.T) {
+	loadSchema(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ns="urn:ebay:apis:eBLBaseComponents" targetNamespace="urn:ebay:apis:eBLBaseComponents" elementFormDefault="qualified">
+<xs:element name="GetOrdersRequest" type="ns:GetOrdersRequestType"/>
+<xs:complexType name="GetOrdersRequestType"><xs:sequence>
+ <xs:element name="OrderStatus" type="ns:OrderStatusCodeType" minOccurs="0"><xs:annotation><xs:appinfo><MaxLength>10</MaxLength><CallInfo><CallName>GetOrders</CallName><RequiredInput>No</RequiredInput><OnlyTheseValues>Active, Completed</OnlyTheseValues></CallInfo></xs:appinfo></xs:annotation></xs:element>
+ <xs:element name="ListingStatus" type="ns:OrderStatusCodeType" minOccurs="0"><xs:annotation><xs:appinfo><MaxLength>10</MaxLength><CallInfo><CallName>GetOrders</CallName><RequiredInput>No</RequiredInput></CallInfo></xs:appinfo></xs:annotation></xs:element>
+</xs:sequence></xs:complexType>
+<xs:simpleType name="OrderStatusCodeType"><xs:restriction base="xs:token">
+ <xs:enumeration value="Active"/><xs:enumeration value="Completed"/><xs:enumeration value="Cancelled"/><xs:enumeration value="CustomCode"/>
+</xs:restriction></xs:simpleType>
+</xs:schema>`, "GetOrders")
+	request, _ := FindComplex("GetOrdersRequestType")
+
+	tests := []struct {
+		field string
+		want  []ValidationType
+	}{
+		{"OrderStatus", []ValidationType{ValTypOnlyTheseValues}},
+		{"ListingStatus", []ValidationType{ValTypEnumeration}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			for _, f := range request.GetElements() {
+				if f.GetName() != tt.field {
+					continue
+				}
+				rules, _ := f.(element).NeedsValidation("GetOrders")
+				var got []ValidationType
+				for _, rule := range rules {
+					got = append(got, rule.Type)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("element.NeedsValidation() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// genericSchema has anonymous types, a reference, choice and all groups and
+// names Go identifiers cannot hold.
+const genericSchema = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:po="urn:example:po" targetNamespace="urn:example:po" elementFormDefault="qualified">
+<xs:element name="comment" type="xs:string"/>
+<xs:element name="purchase-order"><xs:complexType><xs:sequence>
+ <xs:element name="shipTo" type="po:Address"/>
+ <xs:element ref="po:comment" minOccurs="0"/>
+ <xs:element name="status"><xs:simpleType><xs:restriction base="xs:string"><xs:enumeration value="done"/></xs:restriction></xs:simpleType></xs:element>
+ <xs:element name="line.item" maxOccurs="unbounded"><xs:complexType><xs:attribute name="sku" type="xs:string"/></xs:complexType></xs:element>
+</xs:sequence></xs:complexType></xs:element>
+<xs:complexType name="Purchase_order"><xs:sequence><xs:element name="id" type="xs:int"/></xs:sequence></xs:complexType>
+<xs:complexType name="Address"><xs:choice><xs:element name="street" type="xs:string"/><xs:element name="poBox" type="xs:int"/></xs:choice></xs:complexType>
+<xs:complexType name="Contact"><xs:all><xs:element name="email" type="xs:string"/><xs:element name="phone" type="xs:string" minOccurs="0"/></xs:all></xs:complexType>
+</xs:schema>`
+
+func Test_schema_normalise(t *testing.T) {
+	appInfoExt = genericAppInfo{}
+	defer func() { appInfoExt = ebayAppInfo{} }()
+	loadSchema(t, genericSchema)
+
+	tests := []struct {
+		name     string
+		typeName string
+		// fields are "name type minOccurs maxOccurs" for elements and
+		// "name type" for attributes.
+		fields []string
+	}{
+		{"named type", "Purchase_order", []string{"id int  "}},
+		// Hoisted types are named after their element, a number added if the
+		// name is taken, and the enclosing type.
+		{"anonymous type of a top-level element", "Purchase_order2", []string{"shipTo Address  ", "comment string 0 ", "status Purchase_order2Status  ", "line.item Purchase_order2Line_item  unbounded"}},
+		{"anonymous type of a field", "Purchase_order2Line_item", []string{"sku string"}},
+		// Only one member of a choice is set, so all are optional.
+		{"choice", "Address", []string{"street string 0 ", "poBox int 0 "}},
+		{"all", "Contact", []string{"email string  ", "phone string 0 "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := FindComplex(tt.typeName)
+			if !ok {
+				t.Fatalf("no complex type %s", tt.typeName)
+			}
+			var fields []string
+			for _, x := range c.GetElements() {
+				if e, ok := x.(element); ok {
+					fields = append(fields, fmt.Sprintf("%s %s %s %s", e.Name, e.Type, e.MinOccurs, e.MaxOccurs))
+				} else {
+					fields = append(fields, fmt.Sprintf("%s %s", x.GetName(), x.GetType()))
+				}
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields = %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+	if _, ok := FindSimple("Purchase_order2Status"); !ok {
+		t.Error("anonymous simple type of status not hoisted as Purchase_order2Status")
+	}
+
+	FromElements(nil)
+	for typeName, want := range map[string]string{
+		"Purchase_order2": "type Purchase_order2 struct {\r\n\tXMLName\txml.Name `xml:\"urn:example:po purchase-order\" json:\"-\"`\r\n\r\n" +
+			"\tShipTo *Address `xml:\"shipTo,omitempty\" json:\"ship_to,omitempty\"`\r\n" +
+			"\tComment NullString `xml:\"comment,omitempty\" json:\"comment,omitempty\"`\r\n" +
+			"\tStatus Purchase_order2Status `xml:\"status,omitempty\" json:\"status,omitempty\"`\r\n" +
+			"\tLine_item []Purchase_order2Line_item `xml:\"line.item,omitempty\" json:\"line.item,omitempty\"`\r\n}\r\n",
+		"Purchase_order2Line_item": "type Purchase_order2Line_item struct {\r\n" +
+			"\tSku string `xml:\"sku,attr,omitempty\" json:\"sku,omitempty\"` //attribute\r\n}\r\n",
+	} {
+		if got := Types[typeName].String(); got != want {
+			t.Errorf("%s:\n%s\nwant:\n%s", typeName, got, want)
+		}
+	}
+}
+
+func Test_UpperFirstLetter(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"item", "Item"},
+		{"ItemID", "ItemID"},
+		{"purchase-order", "Purchase_order"},
+		{"line.item", "Line_item"},
+		{"x y", "X_y"},
+		{"straße", "Straße"},
+	}
+	for _, tt := range tests {
+		if got := UpperFirstLetter(tt.in); got != tt.want {
+			t.Errorf("UpperFirstLetter(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// The ebay dialect generates the types it did before appinfo dialects were
+// pluggable.
+func Test_ebayAppInfo_types(t *testing.T) {
+	loadSchema(t, requestSchema, "AddItem")
+	FromRequest("AddItem")
+
+	for typeName, want := range map[string]string{
+		"AddItemRequestType": "type AddItemRequestType struct {\r\n\tXMLName\txml.Name `xml:\"AddItemRequest\" json:\"-\"`\r\n\tXmlnsAttr `xml:\"xmlns,attr\" json:\"-\"`\r\n\r\n" +
+			"\tItem *ItemType `xml:\"Item,omitempty\" json:\"item,omitempty\"`\r\n}\r\n",
+		"ItemType": "type ItemType struct {\r\n" +
+			"\tStartPrice *AmountType `xml:\"StartPrice,omitempty\" json:\"start_price,omitempty\"`\r\n" +
+			"\tVariation *VariationType `xml:\"Variation,omitempty\" json:\"variation,omitempty\"`\r\n}\r\n",
+		"AmountType": "type AmountType struct {\r\n" +
+			"\tCurrencyID string `xml:\"currencyID,attr,omitempty\" json:\"currency_id,omitempty\"` //attribute\r\n" +
+			"\tRate float64 `xml:\"rate,attr,omitempty\" json:\"rate,omitempty\"` //attribute\r\n" +
+			"\tValue NullFloat64 `xml:\",chardata\" json:\"value,omitempty\"`\r\n}\r\n",
+		"VariationType": "type VariationType struct {\r\n\tItem *ItemType `xml:\"Item,omitempty\" json:\"item,omitempty\"`\r\n}\r\n",
+	} {
+		if got := Types[typeName].String(); got != want {
+			t.Errorf("%s:\n%s\nwant:\n%s", typeName, got, want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlToJSON converts the YAML subset -rules files use to JSON: block
+// mappings and sequences, flow sequences and mappings ([a, b], {a: 1}),
+// quoted and plain scalars and # comments. Anchors, tags and multi-line
+// scalars are not supported.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var lines []yamlLine
+	for i, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimRight(stripYAMLComment(text), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return []byte("{}"), nil
+	}
+
+	p := &yamlParser{lines: lines}
+	v, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return json.Marshal(v)
+}
+
+type yamlLine struct {
+	number, indent int
+	text           string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block parses the mapping or sequence starting at the current line.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isYAMLItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	list := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		switch {
+		case rest == "":
+			p.pos++
+			v, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		case yamlKeyEnd(rest) > 0:
+			// "- field: Item.ListingType" starts a mapping indented like its first key.
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + len(line.text) - len(rest), text: rest}
+			v, err := p.mapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		default:
+			v, err := yamlScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", line.number, err)
+			}
+			list = append(list, v)
+			p.pos++
+		}
+	}
+	return list, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if isYAMLItem(line.text) {
+			return nil, fmt.Errorf("line %d: sequence item in a mapping", line.number)
+		}
+		end := yamlKeyEnd(line.text)
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		key, err := yamlScalar(line.text[:end])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line.number, err)
+		}
+		name := fmt.Sprint(key)
+		if _, ok := m[name]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %s", line.number, name)
+		}
+
+		value := strings.TrimSpace(line.text[end+1:])
+		p.pos++
+		if value != "" {
+			if m[name], err = yamlScalar(value); err != nil {
+				return nil, fmt.Errorf("line %d: %s", line.number, err)
+			}
+			continue
+		}
+		// A sequence may be indented like its key.
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLItem(p.lines[p.pos].text) {
+			m[name], err = p.sequence(indent)
+		} else {
+			m[name], err = p.nested(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// nested parses the block indented deeper than indent, nil if there is none.
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+		return nil, nil
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlKeyEnd returns the index of the colon ending the key of text, -1 if
+// text is no "key: value" pair.
+func yamlKeyEnd(text string) int {
+	if text == "" || strings.ContainsRune("[{", rune(text[0])) {
+		return -1
+	}
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// stripYAMLComment removes a # comment outside of quotes from text.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func yamlScalar(text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "" || text == "~" || text == "null":
+		return nil, nil
+	case text == "true" || text == "True" || text == "TRUE":
+		return true, nil
+	case text == "false" || text == "False" || text == "FALSE":
+		return false, nil
+	case text[0] == '"':
+		var s string
+		if err := json.Unmarshal([]byte(text), &s); err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return s, nil
+	case text[0] == '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text[0] == '[':
+		if text[len(text)-1] != ']' {
+			return nil, fmt.Errorf("unterminated flow sequence %s", text)
+		}
+		list := []interface{}{}
+		for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+			v, err := yamlScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case text[0] == '{':
+		if text[len(text)-1] != '}' {
+			return nil, fmt.Errorf("unterminated flow mapping %s", text)
+		}
+		m := map[string]interface{}{}
+		for _, item := range splitYAMLFlow(text[1 : len(text)-1]) {
+			end := yamlKeyEnd(item)
+			if end < 0 {
+				return nil, fmt.Errorf("expected \"key: value\" in %s", text)
+			}
+			key, err := yamlScalar(item[:end])
+			if err != nil {
+				return nil, err
+			}
+			if m[fmt.Sprint(key)], err = yamlScalar(item[end+1:]); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return text, nil
+}
+
+// splitYAMLFlow splits the items of a flow sequence or mapping at the commas
+// outside of quotes and nested collections.
+func splitYAMLFlow(text string) (items []string) {
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, text[start:i])
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" || len(items) > 0 {
+		items = append(items, text[start:])
+	}
+	return
+}